@@ -0,0 +1,472 @@
+package admin
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/middleware"
+	"go-fiber-template/lib/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type httpHandler struct {
+	userService        interfaces.UserService
+	roleService        interfaces.RoleService
+	authService        interfaces.AuthService
+	oauthClientService interfaces.OAuthClientService
+	auditService       interfaces.AuditService
+}
+
+func NewHttpHandler(
+	r fiber.Router,
+	userService interfaces.UserService,
+	roleService interfaces.RoleService,
+	authService interfaces.AuthService,
+	oauthClientService interfaces.OAuthClientService,
+	auditService interfaces.AuditService,
+) {
+	handler := &httpHandler{
+		userService:        userService,
+		roleService:        roleService,
+		authService:        authService,
+		oauthClientService: oauthClientService,
+		auditService:       auditService,
+	}
+
+	canReadUsers := middleware.RequirePermission(constant.PermissionUserRead)
+	canWriteUsers := middleware.RequirePermission(constant.PermissionUserWrite)
+	canWriteRoles := middleware.RequirePermission(constant.PermissionRoleWrite)
+	canWriteOAuthClients := middleware.RequirePermission(constant.PermissionOAuthClientWrite)
+
+	// Administration is done as oneself, never through an impersonation token
+	r.Use(middleware.Protected(), middleware.DenyImpersonation())
+	r.Get("/roles", middleware.RequirePermission(constant.PermissionRoleRead), handler.FindAllRoles)
+	r.Get("/users", canReadUsers, middleware.ValidateQuery[dto.UserFilter](), handler.FindAllUsers)
+	r.Post("/users/:id/roles", canWriteRoles, middleware.Validate[dto.AssignRoleRequest](), handler.AssignRole)
+	r.Put("/users/:id/roles", canWriteRoles, middleware.Validate[dto.SetRolesRequest](), handler.SetRoles)
+	r.Delete("/users/:id/roles/:role", canWriteRoles, handler.RemoveRole)
+	r.Post("/users/:id/unlock", canWriteUsers, handler.UnlockUser)
+	r.Post("/users/:id/disable", canWriteUsers, handler.DisableUser)
+	r.Post("/users/:id/enable", canWriteUsers, handler.EnableUser)
+	r.Post("/users/:id/logout", canWriteUsers, handler.LogoutUser)
+	r.Post("/users/:id/impersonate", middleware.RequirePermission(constant.PermissionUserImpersonate), handler.ImpersonateUser)
+	r.Get("/oauth-clients", middleware.RequirePermission(constant.PermissionOAuthClientRead), handler.FindAllOAuthClients)
+	r.Post("/oauth-clients", canWriteOAuthClients, middleware.Validate[dto.CreateOAuthClientRequest](), handler.CreateOAuthClient)
+	r.Delete("/oauth-clients/:id", canWriteOAuthClients, handler.RevokeOAuthClient)
+	r.Get("/audit", middleware.RequirePermission(constant.PermissionAuditRead), middleware.ValidateQuery[dto.AuditFilter](), handler.FindAuditEvents)
+}
+
+// @Summary		List roles
+// @Description	List every role with its permissions
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Success		200	{object}	dto.ResponseDto{data=[]dto.RoleDto}
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		403	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/admin/roles [get]
+func (h *httpHandler) FindAllRoles(c *fiber.Ctx) error {
+	data, err := h.roleService.FindAll(c)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Roles fetched successfully",
+		Data:    data,
+	})
+}
+
+// @Summary		List users
+// @Description	Search, filter and sort the users. Pagination is described by the X-Total-Count, X-Total-Pages, X-Page, X-Limit, X-Next-Page and X-Prev-Page headers.
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			search			query		string	false	"Part of the name or email"
+// @Param			verified		query		bool	false	"Only verified or unverified users"
+// @Param			disabled		query		bool	false	"Only disabled or enabled users"
+// @Param			deleted			query		string	false	"Deleted users"	Enums(exclude, include, only)	default(exclude)
+// @Param			created_from	query		string	false	"Created on or after (YYYY-MM-DD)"
+// @Param			created_to		query		string	false	"Created on or before (YYYY-MM-DD)"
+// @Param			sort			query		string	false	"Sort by"	Enums(id, name, email, created_at)	default(created_at)
+// @Param			order			query		string	false	"Sort order"	Enums(asc, desc)
+// @Param			page			query		int		false	"Page"	default(1)
+// @Param			limit			query		int		false	"Users per page"	default(20)
+// @Success		200				{object}	dto.ResponseDto{data=[]dto.AdminUserDto}
+// @Failure		400				{object}	dto.ResponseDto
+// @Failure		401				{object}	dto.ResponseDto
+// @Failure		403				{object}	dto.ResponseDto
+// @Failure		422				{object}	dto.ResponseDto
+// @Failure		500				{object}	dto.ResponseDto
+// @Router			/admin/users [get]
+func (h *httpHandler) FindAllUsers(c *fiber.Ctx) error {
+	filter := utils.ExtractStructFromValidator[dto.UserFilter](c)
+	data, err := h.userService.Search(c, filter)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Users fetched successfully",
+		Data:    data,
+	})
+}
+
+// @Summary		Assign role
+// @Description	Assign a role to a user. It takes effect on the next token the user obtains.
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id		path		int						true	"User ID"
+// @Param			request	body		dto.AssignRoleRequest	true	"Assign role request"
+// @Success		200		{object}	dto.ResponseDto
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		403		{object}	dto.ResponseDto
+// @Failure		404		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/admin/users/{id}/roles [post]
+func (h *httpHandler) AssignRole(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	req := utils.ExtractStructFromValidator[dto.AssignRoleRequest](c)
+	if err := h.roleService.AssignToUser(c, uint(id), req); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Role assigned successfully",
+	})
+}
+
+// @Summary		Set roles
+// @Description	Replace every role of a user with the given ones. It takes effect on the next token the user obtains.
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id		path		int						true	"User ID"
+// @Param			request	body		dto.SetRolesRequest		true	"Set roles request"
+// @Success		200		{object}	dto.ResponseDto
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		403		{object}	dto.ResponseDto
+// @Failure		404		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/admin/users/{id}/roles [put]
+func (h *httpHandler) SetRoles(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	req := utils.ExtractStructFromValidator[dto.SetRolesRequest](c)
+	if err := h.roleService.SetUserRoles(c, uint(id), req); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Roles updated successfully",
+	})
+}
+
+// @Summary		Remove role
+// @Description	Remove a role from a user
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id		path		int		true	"User ID"
+// @Param			role	path		string	true	"Role name"
+// @Success		200		{object}	dto.ResponseDto
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		403		{object}	dto.ResponseDto
+// @Failure		404		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/admin/users/{id}/roles/{role} [delete]
+func (h *httpHandler) RemoveRole(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	if err := h.roleService.RemoveFromUser(c, uint(id), c.Params("role")); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Role removed successfully",
+	})
+}
+
+// @Summary		Unlock user
+// @Description	Lift the lockout of a user account caused by too many failed login attempts
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id	path		int	true	"User ID"
+// @Success		200	{object}	dto.ResponseDto
+// @Failure		400	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		403	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/admin/users/{id}/unlock [post]
+func (h *httpHandler) UnlockUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	if err := h.authService.Unlock(c, uint(id)); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "User unlocked successfully",
+	})
+}
+
+// @Summary		Disable user
+// @Description	Keep a user from logging in or using their API keys until they are enabled again. The user is logged out everywhere.
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id	path		int	true	"User ID"
+// @Success		200	{object}	dto.ResponseDto
+// @Failure		400	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		403	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/admin/users/{id}/disable [post]
+func (h *httpHandler) DisableUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	if err := h.authService.Disable(c, uint(id)); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "User disabled successfully",
+	})
+}
+
+// @Summary		Enable user
+// @Description	Allow a disabled user to log in again
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id	path		int	true	"User ID"
+// @Success		200	{object}	dto.ResponseDto
+// @Failure		400	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		403	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/admin/users/{id}/enable [post]
+func (h *httpHandler) EnableUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	if err := h.authService.Enable(c, uint(id)); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "User enabled successfully",
+	})
+}
+
+// @Summary		Log user out
+// @Description	Revoke every token and session of a user, logging them out on every device
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id	path		int	true	"User ID"
+// @Success		200	{object}	dto.ResponseDto
+// @Failure		400	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		403	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/admin/users/{id}/logout [post]
+func (h *httpHandler) LogoutUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	if err := h.authService.ForceLogout(c, uint(id)); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "User logged out successfully",
+	})
+}
+
+// @Summary		Impersonate user
+// @Description	Get a short-lived access token acting as a user, to reproduce what they see. Every request made with it is logged with the identity of the administrator, and sensitive actions such as changing the password, two-factor authentication, managing sessions or deleting the account are refused. Administrators cannot be impersonated.
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id	path		int	true	"User ID"
+// @Success		200	{object}	dto.ResponseDto{data=dto.ImpersonationResponse}
+// @Failure		400	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		403	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/admin/users/{id}/impersonate [post]
+func (h *httpHandler) ImpersonateUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID")
+	}
+
+	data, err := h.authService.Impersonate(c, uint(id))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Impersonation started",
+		Data:    data,
+	})
+}
+
+// @Summary		List OAuth clients
+// @Description	List every registered OAuth client, including revoked ones
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Success		200	{object}	dto.ResponseDto{data=[]dto.OAuthClientDto}
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		403	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/admin/oauth-clients [get]
+func (h *httpHandler) FindAllOAuthClients(c *fiber.Ctx) error {
+	data, err := h.oauthClientService.FindAll(c)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "OAuth clients found",
+		Data:    data,
+	})
+}
+
+// @Summary		Register OAuth client
+// @Description	Register a machine identity for another service, which gets access tokens for the given scopes at /oauth/token. The client secret is only returned once.
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			request	body		dto.CreateOAuthClientRequest	true	"Create OAuth client request"
+// @Success		201		{object}	dto.ResponseDto{data=dto.CreateOAuthClientResponse}
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		403		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/admin/oauth-clients [post]
+func (h *httpHandler) CreateOAuthClient(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.CreateOAuthClientRequest](c)
+	data, err := h.oauthClientService.Create(c, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.ResponseDto{
+		Message: "OAuth client registered successfully",
+		Data:    data,
+	})
+}
+
+// @Summary		Revoke OAuth client
+// @Description	Revoke an OAuth client. It gets no new tokens and the ones it has are rejected.
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id	path		int	true	"OAuth client ID"
+// @Success		200	{object}	dto.ResponseDto
+// @Failure		400	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		403	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/admin/oauth-clients/{id} [delete]
+func (h *httpHandler) RevokeOAuthClient(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid OAuth client ID")
+	}
+
+	if err := h.oauthClientService.Revoke(c, uint(id)); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "OAuth client revoked successfully",
+	})
+}
+
+// @Summary		List audit events
+// @Description	Search the security audit log. Pagination is described by the X-Total-Count, X-Total-Pages, X-Page, X-Limit, X-Next-Page and X-Prev-Page headers.
+// @Tags			Admin
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			actor_id		query		int		false	"ID of the user who acted"
+// @Param			action			query		string	false	"Action, or group of actions when it ends with a dot such as auth."
+// @Param			target_type		query		string	false	"Type of the target"	Enums(user, product)
+// @Param			target_id		query		string	false	"ID of the target"
+// @Param			ip_address		query		string	false	"IP address the request came from"
+// @Param			request_id		query		string	false	"ID of the request"
+// @Param			created_from	query		string	false	"Recorded on or after (YYYY-MM-DD)"
+// @Param			created_to		query		string	false	"Recorded on or before (YYYY-MM-DD)"
+// @Param			order			query		string	false	"Sort order"	Enums(asc, desc)	default(desc)
+// @Param			page			query		int		false	"Page"	default(1)
+// @Param			limit			query		int		false	"Events per page"	default(20)
+// @Success		200				{object}	dto.ResponseDto{data=[]dto.AuditEventDto}
+// @Failure		401				{object}	dto.ResponseDto
+// @Failure		403				{object}	dto.ResponseDto
+// @Failure		422				{object}	dto.ResponseDto
+// @Failure		500				{object}	dto.ResponseDto
+// @Router			/admin/audit [get]
+func (h *httpHandler) FindAuditEvents(c *fiber.Ctx) error {
+	filter := utils.ExtractStructFromValidator[dto.AuditFilter](c)
+	data, err := h.auditService.Search(c, filter)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Audit events fetched successfully",
+		Data:    data,
+	})
+}
@@ -0,0 +1,103 @@
+package apikey
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/middleware"
+	"go-fiber-template/lib/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type httpHandler struct {
+	apiKeyService interfaces.APIKeyService
+}
+
+func NewHttpHandler(r fiber.Router, apiKeyService interfaces.APIKeyService) {
+	handler := &httpHandler{
+		apiKeyService: apiKeyService,
+	}
+
+	// Managing keys requires an interactive login of the user themselves, an API key cannot
+	// mint other keys and neither can an administrator impersonating the user
+	r.Use(middleware.Protected(), middleware.DenyImpersonation())
+	r.Post("/", middleware.Validate[dto.CreateAPIKeyRequest](), handler.Create)
+	r.Get("/", handler.FindAll)
+	r.Delete("/:id", handler.Revoke)
+}
+
+// @Summary		Create API key
+// @Description	Create an API key for the current user. The key is only returned in this response.
+// @Tags			API Key
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			request	body		dto.CreateAPIKeyRequest	true	"Create API key request"
+// @Success		201		{object}	dto.ResponseDto{data=dto.CreateAPIKeyResponse}
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/api-keys [post]
+func (h *httpHandler) Create(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.CreateAPIKeyRequest](c)
+	data, err := h.apiKeyService.Create(c, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.ResponseDto{
+		Message: "API key created successfully",
+		Data:    data,
+	})
+}
+
+// @Summary		List API keys
+// @Description	List the active API keys of the current user
+// @Tags			API Key
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Success		200	{object}	dto.ResponseDto{data=[]dto.APIKeyDto}
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/api-keys [get]
+func (h *httpHandler) FindAll(c *fiber.Ctx) error {
+	data, err := h.apiKeyService.FindAll(c)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "API keys fetched successfully",
+		Data:    data,
+	})
+}
+
+// @Summary		Revoke API key
+// @Description	Revoke an API key of the current user
+// @Tags			API Key
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id	path		int	true	"API key ID"
+// @Success		200	{object}	dto.ResponseDto
+// @Failure		400	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/api-keys/{id} [delete]
+func (h *httpHandler) Revoke(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid API key ID")
+	}
+
+	if err := h.apiKeyService.Revoke(c, uint(id)); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "API key revoked successfully",
+	})
+}
@@ -0,0 +1,208 @@
+package apikey
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// keyPrefix makes our keys recognizable, e.g. by secret scanners.
+const keyPrefix = "gft_"
+
+// visiblePrefixLength is the number of leading characters of a key kept in clear text
+// so that users can tell their keys apart.
+const visiblePrefixLength = 12
+
+type service struct {
+	apiKeyRepo   interfaces.APIKeyRepository
+	userRepo     interfaces.UserRepository
+	roleRepo     interfaces.RoleRepository
+	auditService interfaces.AuditService
+}
+
+func (s *service) Create(c *fiber.Ctx, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	user, _ := s.userRepo.FindByID(claims.UserID())
+	if user == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Expiry must be in the future")
+	}
+
+	if err := s.validateScopes(user, req.Scopes); err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	key := keyPrefix + secret
+
+	apiKey := &entity.APIKey{
+		UserID:    user.ID,
+		Name:      req.Name,
+		Prefix:    key[:visiblePrefixLength],
+		KeyHash:   utils.HashToken(key),
+		Scopes:    strings.Join(req.Scopes, ","),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.apiKeyRepo.Create(apiKey); err != nil {
+		return nil, err
+	}
+
+	apiKeyDto := constructAPIKeyDto(apiKey)
+	s.audit(c, constant.AuditActionAPIKeyCreated, apiKey.ID, nil, apiKeyDto)
+
+	return &dto.CreateAPIKeyResponse{
+		APIKeyDto: *apiKeyDto,
+		Key:       key,
+	}, nil
+}
+
+func (s *service) FindAll(c *fiber.Ctx) ([]dto.APIKeyDto, error) {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	apiKeys, err := s.apiKeyRepo.FindActiveByUserID(claims.UserID())
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeyDtos := make([]dto.APIKeyDto, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		apiKeyDtos = append(apiKeyDtos, *constructAPIKeyDto(&apiKey))
+	}
+
+	return apiKeyDtos, nil
+}
+
+func (s *service) Revoke(c *fiber.Ctx, id uint) error {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	revoked, err := s.apiKeyRepo.Revoke(id, claims.UserID())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return fiber.NewError(fiber.StatusNotFound, "API key not found")
+	}
+
+	now := time.Now()
+	s.audit(c, constant.AuditActionAPIKeyRevoked, id, map[string]*string{"revoked_at": nil}, map[string]*string{"revoked_at": formatTime(&now)})
+	return nil
+}
+
+func (s *service) Authenticate(c *fiber.Ctx, key string) (*xjwt.TokenClaims, error) {
+	apiKey, err := s.apiKeyRepo.FindByKeyHash(utils.HashToken(key))
+	if err != nil || apiKey.RevokedAt != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired API key")
+	}
+	if apiKey.ExpiresAt != nil && apiKey.ExpiresAt.Before(time.Now()) {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired API key")
+	}
+
+	user, err := s.userRepo.FindByID(apiKey.UserID)
+	if err != nil || user.DisabledAt != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired API key")
+	}
+
+	if err := s.apiKeyRepo.Touch(apiKey.ID); err != nil {
+		log.Error().Err(err).Uint("api_key_id", apiKey.ID).Msg("Failed to update API key last use")
+	}
+
+	return xjwt.NewClaims(user, xjwt.TokenTypeAPIKey, xjwt.WithScopes(apiKey.ScopeList())), nil
+}
+
+// validateScopes makes sure a key cannot grant more than its owner is allowed to do.
+func (s *service) validateScopes(user *entity.User, scopes []string) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	permissions, err := s.roleRepo.FindPermissionNames(user.RoleNames())
+	if err != nil {
+		return err
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(permissions, scope) {
+			return fiber.NewError(fiber.StatusBadRequest, "Scope not granted to the user: "+scope)
+		}
+	}
+
+	return nil
+}
+
+// audit records a change of the API key, with the fields that changed. Either value may be nil.
+func (s *service) audit(c *fiber.Ctx, action string, id uint, before, after any) {
+	record := &interfaces.AuditRecord{
+		Action:     action,
+		TargetType: constant.AuditTargetAPIKey,
+		TargetID:   strconv.Itoa(int(id)),
+	}
+
+	diff, err := utils.Diff(before, after, "created_at", "last_used_at")
+	if err != nil {
+		log.Error().Err(err).Uint("api_key_id", id).Msg("Failed to compute API key diff")
+	} else {
+		record.Diff = diff
+	}
+
+	s.auditService.Record(c, record)
+}
+
+func constructAPIKeyDto(apiKey *entity.APIKey) *dto.APIKeyDto {
+	return &dto.APIKeyDto{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.ScopeList(),
+		ExpiresAt:  formatTime(apiKey.ExpiresAt),
+		LastUsedAt: formatTime(apiKey.LastUsedAt),
+		CreatedAt:  apiKey.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format("2006-01-02 15:04:05")
+	return &formatted
+}
+
+func NewService(
+	apiKeyRepo interfaces.APIKeyRepository,
+	userRepo interfaces.UserRepository,
+	roleRepo interfaces.RoleRepository,
+	auditService interfaces.AuditService,
+) interfaces.APIKeyService {
+	return &service{
+		apiKeyRepo:   apiKeyRepo,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		auditService: auditService,
+	}
+}
@@ -0,0 +1,62 @@
+package apikey
+
+import (
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func (r *repository) Create(data *entity.APIKey) error {
+	return r.db.Create(data).Error
+}
+
+func (r *repository) FindByKeyHash(keyHash string) (*entity.APIKey, error) {
+	var apiKey entity.APIKey
+	if err := r.db.Where("key_hash = ?", keyHash).First(&apiKey).Error; err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r *repository) FindActiveByUserID(userID uint) ([]entity.APIKey, error) {
+	var apiKeys []entity.APIKey
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&apiKeys).Error
+	if err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (r *repository) FindByUserID(userID uint) ([]entity.APIKey, error) {
+	var apiKeys []entity.APIKey
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&apiKeys).Error; err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (r *repository) Revoke(id uint, userID uint) (bool, error) {
+	result := r.db.Model(&entity.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) Touch(id uint) error {
+	return r.db.Model(&entity.APIKey{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
+}
+
+func NewRepository(db *gorm.DB) interfaces.APIKeyRepository {
+	return &repository{db: db}
+}
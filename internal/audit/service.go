@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"encoding/json"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// maxRequestIDLength is the size of the request_id column.
+const maxRequestIDLength = 64
+
+type service struct {
+	auditRepo interfaces.AuditRepository
+}
+
+func (s *service) Record(c *fiber.Ctx, record *interfaces.AuditRecord) {
+	event := &entity.AuditEvent{
+		ActorID:    record.ActorID,
+		Action:     record.Action,
+		TargetType: record.TargetType,
+		TargetID:   record.TargetID,
+		IPAddress:  c.IP(),
+		UserAgent:  c.Get(fiber.HeaderUserAgent),
+		RequestID:  c.GetRespHeader(fiber.HeaderXRequestID),
+	}
+	// Request IDs sent by the client are kept by the requestid middleware, whatever their length
+	if len(event.RequestID) > maxRequestIDLength {
+		event.RequestID = event.RequestID[:maxRequestIDLength]
+	}
+
+	// Tokens of OAuth clients have no user as their subject, so they leave the actor unknown
+	if claims := xjwt.ExtractTokenFromCtx(c); claims != nil && claims.UserID() != 0 {
+		if event.ActorID == nil {
+			actorID := claims.UserID()
+			event.ActorID = &actorID
+		}
+		if claims.Impersonated() {
+			impersonatorID := claims.ActorID()
+			event.ImpersonatorID = &impersonatorID
+		}
+	}
+
+	if record.Diff != nil {
+		diff, err := json.Marshal(record.Diff)
+		if err != nil {
+			log.Error().Err(err).Str("action", record.Action).Msg("Failed to encode audit event diff")
+		} else {
+			encoded := string(diff)
+			event.Diff = &encoded
+		}
+	}
+
+	if err := s.auditRepo.Create(event); err != nil {
+		log.Error().Err(err).
+			Str("action", record.Action).
+			Str("target_type", record.TargetType).
+			Str("target_id", record.TargetID).
+			Msg("Failed to record audit event")
+	}
+}
+
+func (s *service) Search(c *fiber.Ctx, filter *dto.AuditFilter) ([]dto.AuditEventDto, error) {
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
+
+	events, total, err := s.auditRepo.Search(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	eventDtos := make([]dto.AuditEventDto, 0, len(events))
+	for _, event := range events {
+		eventDtos = append(eventDtos, *constructAuditEventDto(&event))
+	}
+
+	utils.SetPaginationHeader(c, filter.Page, filter.Limit, int(total))
+	return eventDtos, nil
+}
+
+func (s *service) PurgeExpired() error {
+	if config.Config.Audit.Retention == 0 {
+		return nil
+	}
+
+	retention := time.Duration(config.Config.Audit.Retention) * time.Second
+	return s.auditRepo.DeleteBefore(time.Now().Add(-retention))
+}
+
+func constructAuditEventDto(event *entity.AuditEvent) *dto.AuditEventDto {
+	eventDto := &dto.AuditEventDto{
+		ID:             event.ID,
+		ActorID:        event.ActorID,
+		ImpersonatorID: event.ImpersonatorID,
+		Action:         event.Action,
+		TargetType:     event.TargetType,
+		TargetID:       event.TargetID,
+		IPAddress:      event.IPAddress,
+		UserAgent:      event.UserAgent,
+		RequestID:      event.RequestID,
+		CreatedAt:      event.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if event.Diff != nil {
+		eventDto.Diff = json.RawMessage(*event.Diff)
+	}
+
+	return eventDto
+}
+
+func NewService(auditRepo interfaces.AuditRepository) interfaces.AuditService {
+	return &service{
+		auditRepo: auditRepo,
+	}
+}
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/database"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func (r *repository) Create(data *entity.AuditEvent) error {
+	return r.db.Create(data).Error
+}
+
+func (r *repository) Search(filter *dto.AuditFilter) ([]entity.AuditEvent, int64, error) {
+	query := r.db.Model(&entity.AuditEvent{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if strings.HasSuffix(filter.Action, ".") {
+		query = query.Where(database.HasPrefix("action", filter.Action))
+	} else if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.IPAddress != "" {
+		query = query.Where("ip_address = ?", filter.IPAddress)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if from, err := time.Parse("2006-01-02", filter.CreatedFrom); err == nil {
+		query = query.Where("created_at >= ?", from)
+	}
+	if to, err := time.Parse("2006-01-02", filter.CreatedTo); err == nil {
+		// The range includes the whole last day
+		query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "id DESC"
+	if filter.Order == "asc" {
+		order = "id ASC"
+	}
+
+	var events []entity.AuditEvent
+	err := query.Order(order).
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+func (r *repository) FindByUserID(userID uint) ([]entity.AuditEvent, error) {
+	var events []entity.AuditEvent
+	err := r.db.Where("actor_id = ? OR (target_type = ? AND target_id = ?)", userID, constant.AuditTargetUser, strconv.Itoa(int(userID))).
+		Order("id").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *repository) DeleteBefore(before time.Time) error {
+	return r.db.Where("created_at < ?", before).Delete(&entity.AuditEvent{}).Error
+}
+
+func NewRepository(db *gorm.DB) interfaces.AuditRepository {
+	return &repository{db: db}
+}
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"fmt"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xpassword"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+func (s *service) ChangePassword(c *fiber.Ctx, req *dto.ChangePasswordRequest) (*dto.LoginResponse, error) {
+	user, err := s.currentUser(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Current password is incorrect")
+	}
+
+	if err := xpassword.CheckPolicy(req.NewPassword, user.Name, user.Email); err != nil {
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	hash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = hash
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.resetTokenRepo.InvalidateByUserID(user.ID); err != nil {
+		return nil, err
+	}
+
+	// Whoever knew the old password must not stay logged in, the caller gets a fresh session instead
+	if err := s.revokeAllTokens(user.ID); err != nil {
+		return nil, err
+	}
+	s.audit(c, constant.AuditActionPasswordChanged, user.ID, nil)
+
+	// The revocation covers every token issued within its second, the fresh session starts in the next one
+	time.Sleep(time.Until(revocationCutoff(time.Now()).Add(time.Second)))
+
+	return s.startSession(c, user)
+}
+
+func (s *service) ChangeEmail(c *fiber.Ctx, req *dto.ChangeEmailRequest) error {
+	user, err := s.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		return fiber.NewError(fiber.StatusBadRequest, "Current password is incorrect")
+	}
+
+	if strings.EqualFold(req.Email, user.Email) {
+		return fiber.NewError(fiber.StatusBadRequest, "New email address is the current one")
+	}
+
+	if err := s.validateUnique(&entity.User{Model: user.Model, Email: req.Email}); err != nil {
+		return err
+	}
+
+	user.PendingEmail = req.Email
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	// The link is sent to the new address, proving that the user owns it
+	pending := *user
+	pending.Email = req.Email
+	return s.sendVerificationEmail(c, &pending)
+}
+
+// confirmEmailChange replaces the email address of the user by the pending one once it is verified.
+func (s *service) confirmEmailChange(c *fiber.Ctx, user *entity.User) error {
+	oldEmail := user.Email
+
+	if err := s.validateUnique(&entity.User{Model: user.Model, Email: user.PendingEmail}); err != nil {
+		return err
+	}
+
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	s.auditSelf(c, constant.AuditActionEmailChanged, user.ID, map[string]utils.FieldChange{
+		"email": {Old: oldEmail, New: user.Email},
+	})
+
+	emailConfig := &interfaces.EmailConfig{
+		To:      oldEmail,
+		Subject: "Your email address was changed",
+		Body:    fmt.Sprintf("Hello %s, the email address of your account was changed to %s. If this was not you, contact us right away.", user.Name, user.Email),
+	}
+	if err := s.publishEmail(c, "auth.email_changed", emailConfig); err != nil {
+		log.Error().Err(err).Uint("user_id", user.ID).Msg("Failed to send email change notification")
+	}
+
+	return nil
+}
+
+func (s *service) DeleteAccount(c *fiber.Ctx, req *dto.DeleteAccountRequest) (*dto.DeleteAccountResponse, error) {
+	user, err := s.currentUser(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Current password is incorrect")
+	}
+
+	if err := s.userRepo.Delete(user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.resetTokenRepo.InvalidateByUserID(user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.revokeAllTokens(user.ID); err != nil {
+		return nil, err
+	}
+	s.audit(c, constant.AuditActionAccountDeleted, user.ID, nil)
+
+	gracePeriod := time.Duration(config.Config.Auth.DeletionGracePeriod) * time.Second
+	return &dto.DeleteAccountResponse{
+		PurgeAt: time.Now().Add(gracePeriod).Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+// findRestorable finds a deleted user that can still be restored by logging in.
+func (s *service) findRestorable(email string) *entity.User {
+	user, _ := s.userRepo.FindDeletedByEmail(email)
+	if user == nil {
+		return nil
+	}
+
+	gracePeriod := time.Duration(config.Config.Auth.DeletionGracePeriod) * time.Second
+	if user.DeletedAt.Time.Add(gracePeriod).Before(time.Now()) {
+		return nil
+	}
+
+	return user
+}
+
+// restoreAccount undoes the deletion of a user who logged in during the grace period.
+func (s *service) restoreAccount(c *fiber.Ctx, user *entity.User) error {
+	if err := s.userRepo.Restore(user.ID); err != nil {
+		return err
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	s.auditSelf(c, constant.AuditActionAccountRestored, user.ID, nil)
+
+	emailConfig := &interfaces.EmailConfig{
+		To:      user.Email,
+		Subject: "Your account was restored",
+		Body:    fmt.Sprintf("Hello %s, your account was restored because you logged in before its deletion was final. Delete it again if you still want it gone.", user.Name),
+	}
+	if err := s.publishEmail(c, "auth.account_restored", emailConfig); err != nil {
+		log.Error().Err(err).Uint("user_id", user.ID).Msg("Failed to send account restored notification")
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package auth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-fiber-template/internal/audit"
+	"go-fiber-template/internal/auth"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/role"
+	"go-fiber-template/internal/session"
+	"go-fiber-template/internal/user"
+	"go-fiber-template/lib/common"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/middleware"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"go-fiber-template/lib/xkafka"
+	"go-fiber-template/lib/xoidc"
+	"go-fiber-template/lib/xoidc/xoidctest"
+	"go-fiber-template/lib/xtotp"
+	"go-fiber-template/lib/xvalidator"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cache"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// producer drops the messages the service publishes.
+type producer struct {
+	sarama.SyncProducer
+
+	mu     sync.Mutex
+	topics []string
+}
+
+func (p *producer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.topics = append(p.topics, msg.Topic)
+	return 0, 0, nil
+}
+
+// authTest serves the auth and user routes the way the server does, behind the cache and CSRF middlewares,
+// on a fresh SQLite database.
+type authTest struct {
+	app      *fiber.App
+	db       *gorm.DB
+	provider *xoidctest.Server
+	totp     *xtotp.TOTP
+}
+
+func newAuthTest(t *testing.T) *authTest {
+	t.Helper()
+
+	config.Config.BaseURL = "http://localhost:3000"
+	config.Config.Jwt = config.JwtConfig{
+		Algorithm:          "HS256",
+		SecretKey:          "test",
+		ExpiredAt:          60,
+		RefreshExpiredAt:   600,
+		MfaExpiredAt:       60,
+		MagicLinkExpiredAt: 600,
+	}
+	config.Config.Auth = config.AuthConfig{
+		EmailVerificationPolicy:  "optional",
+		DefaultRole:              "user",
+		LockoutThreshold:         5,
+		LockoutDuration:          900,
+		IPLockoutThreshold:       20,
+		MagicLinkRateLimit:       5,
+		MagicLinkRateWindow:      600,
+		PasswordResetRateLimit:   3,
+		PasswordResetIPRateLimit: 5,
+		PasswordResetRateWindow:  600,
+	}
+	config.Config.OAuth.StateExpiredAt = 600
+	xjwt.Setup(config.Config.Jwt)
+	xvalidator.Setup()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(
+		&entity.Permission{},
+		&entity.Role{},
+		&entity.User{},
+		&entity.Session{},
+		&entity.RefreshToken{},
+		&entity.PasswordResetToken{},
+		&entity.RevokedToken{},
+		&entity.RecoveryCode{},
+		&entity.LoginAttempt{},
+		&entity.OAuthState{},
+		&entity.ExternalIdentity{},
+		&entity.AuditEvent{},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&entity.Role{Name: "user"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := xoidctest.NewServer("client", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	providers, err := xoidc.NewProviders(server.Config("fake", "http://localhost:3000/api/v1/auth/oauth/fake/callback"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totp := xtotp.New()
+	auditService := audit.NewService(audit.NewRepository(db))
+	revocationStore := auth.NewSQLRevocationStore(db)
+	sessionRepository := session.NewRepository(db)
+	refreshTokenRepository := auth.NewRefreshTokenRepository(db)
+	authService := auth.NewService(
+		user.NewRepository(db),
+		role.NewRepository(db),
+		refreshTokenRepository,
+		auth.NewPasswordResetTokenRepository(db),
+		auth.NewRecoveryCodeRepository(db),
+		auth.NewLoginAttemptRepository(db),
+		sessionRepository,
+		revocationStore,
+		auth.NewOAuthStateRepository(db),
+		auth.NewExternalIdentityRepository(db),
+		providers,
+		totp,
+		xkafka.NewClientWithProducer(&producer{}),
+		auditService,
+	)
+	middleware.Setup(middleware.Config{
+		RevocationStore: revocationStore,
+		SessionService:  session.NewService(sessionRepository, refreshTokenRepository, auditService),
+	})
+
+	app := fiber.New(fiber.Config{ErrorHandler: common.ErrorHandler})
+	app.Use(cache.New(config.CacheCfg))
+	api := app.Group("/api/v1", middleware.CSRF())
+	auth.NewHttpHandler(api.Group("/auth"), authService)
+	user.NewHttpHandler(api.Group("/users"), user.NewService(user.NewRepository(db), nil, nil), authService)
+
+	return &authTest{app: app, db: db, provider: server, totp: totp}
+}
+
+// createUser stores a verified user that logs in with the given password.
+func (at *authTest) createUser(t *testing.T, email, password string) *entity.User {
+	t.Helper()
+
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	u := &entity.User{Name: email, Email: email, Password: hash, VerifiedAt: &now}
+	if err := at.db.Create(u).Error; err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// send makes a request with a JSON body, if any, and decodes the data of the response into data.
+func (at *authTest) send(t *testing.T, req *http.Request, body any, data any) *http.Response {
+	t.Helper()
+
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(payload))
+		req.ContentLength = int64(len(payload))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+
+	resp, err := at.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != nil {
+		envelope := struct {
+			Data any `json:"data"`
+		}{Data: data}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp
+}
+
+// post sends a JSON body to the path and decodes the data of the response into data.
+func (at *authTest) post(t *testing.T, path string, body any, data any) *http.Response {
+	t.Helper()
+	return at.send(t, httptest.NewRequest(fiber.MethodPost, path, nil), body, data)
+}
+
+// postAs sends a JSON body to the path on behalf of the user the access token was issued to.
+func (at *authTest) postAs(t *testing.T, token, path string, body any, data any) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPost, path, nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	return at.send(t, req, body, data)
+}
+
+// passwordLogin logs the user in with the password and returns their tokens.
+func (at *authTest) passwordLogin(t *testing.T, email, password string) *dto.LoginResponse {
+	t.Helper()
+
+	var data dto.LoginResponse
+	resp := at.post(t, "/api/v1/auth/login", dto.LoginRequest{Email: email, Password: password}, &data)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("login status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+	return &data
+}
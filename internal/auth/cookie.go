@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// refreshCookiePath keeps the refresh token cookie from being sent anywhere but to the auth routes.
+const refreshCookiePath = "/api/v1/auth"
+
+// setTokenCookies stores the tokens in cookies in cookie mode and returns the CSRF token the client
+// has to send along with them in place of the tokens, which scripts must not get to read. A new
+// session gets a new CSRF token, refreshes keep the current one so that other tabs holding it keep working.
+func setTokenCookies(c *fiber.Ctx, tokens *dto.LoginResponse, newSession bool) error {
+	if !config.Config.Auth.CookieMode {
+		return nil
+	}
+
+	csrfToken := c.Cookies(constant.CookieCSRFToken)
+	if newSession || csrfToken == "" {
+		var err error
+		csrfToken, err = utils.GenerateRandomToken(32)
+		if err != nil {
+			return err
+		}
+	}
+
+	refreshLifetime := xjwt.TTL(xjwt.TokenTypeRefresh)
+	c.Cookie(newCookie(constant.CookieAccessToken, tokens.AccessToken, "/", xjwt.TTL(xjwt.TokenTypeAccess), true))
+	c.Cookie(newCookie(constant.CookieRefreshToken, tokens.RefreshToken, refreshCookiePath, refreshLifetime, true))
+	// Scripts of the frontend read this one to echo it in the X-CSRF-Token header
+	c.Cookie(newCookie(constant.CookieCSRFToken, csrfToken, "/", refreshLifetime, false))
+
+	tokens.AccessToken = ""
+	tokens.RefreshToken = ""
+	tokens.CSRFToken = csrfToken
+	return nil
+}
+
+// clearTokenCookies deletes the cookies set by setTokenCookies.
+func clearTokenCookies(c *fiber.Ctx) {
+	if !config.Config.Auth.CookieMode {
+		return
+	}
+
+	for _, cookie := range []*fiber.Cookie{
+		newCookie(constant.CookieAccessToken, "", "/", 0, true),
+		newCookie(constant.CookieRefreshToken, "", refreshCookiePath, 0, true),
+		newCookie(constant.CookieCSRFToken, "", "/", 0, false),
+	} {
+		cookie.Expires = time.Unix(0, 0)
+		c.Cookie(cookie)
+	}
+}
+
+func newCookie(name, value, path string, lifetime time.Duration, httpOnly bool) *fiber.Cookie {
+	return &fiber.Cookie{
+		Name:   name,
+		Value:  value,
+		Path:   path,
+		Domain: config.Config.Auth.CookieDomain,
+		MaxAge: int(lifetime.Seconds()),
+		// Browsers accept secure cookies from http://localhost, so this does not get in the way of development
+		Secure:   true,
+		HTTPOnly: httpOnly,
+		SameSite: config.Config.Auth.CookieSameSite,
+	}
+}
+
+func (s *service) CSRFToken(c *fiber.Ctx) (*dto.CSRFTokenResponse, error) {
+	if !config.Config.Auth.CookieMode {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Cookie authentication is disabled")
+	}
+
+	csrfToken := c.Cookies(constant.CookieCSRFToken)
+	if csrfToken == "" || c.Cookies(constant.CookieRefreshToken) == "" {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Not logged in")
+	}
+
+	return &dto.CSRFTokenResponse{CSRFToken: csrfToken}, nil
+}
@@ -20,6 +20,23 @@ func NewHttpHandler(r fiber.Router, authService interfaces.AuthService) {
 
 	r.Post("/register", middleware.Validate[dto.RegisterRequest](), handler.Register)
 	r.Post("/login", middleware.Validate[dto.LoginRequest](), handler.Login)
+	r.Post("/magic-link", middleware.Validate[dto.MagicLinkRequest](), handler.RequestMagicLink)
+	r.Get("/magic-link/callback", handler.MagicLinkLogin)
+	r.Get("/oauth/:provider", handler.OAuthLogin)
+	r.Get("/oauth/:provider/callback", middleware.ValidateQuery[dto.OAuthCallbackRequest](), handler.OAuthCallback)
+	r.Post("/refresh", middleware.Validate[dto.RefreshTokenRequest](), handler.Refresh)
+	r.Post("/logout", middleware.Protected(), handler.Logout)
+	r.Post("/logout-all", middleware.Protected(), middleware.DenyImpersonation(), handler.LogoutAll)
+	r.Get("/csrf", handler.CSRFToken)
+	r.Get("/verify", handler.VerifyEmail)
+	r.Post("/verify/resend", middleware.Validate[dto.ResendVerificationRequest](), handler.ResendVerification)
+	r.Post("/password/forgot", middleware.Validate[dto.ForgotPasswordRequest](), handler.ForgotPassword)
+	r.Post("/password/reset", middleware.Validate[dto.ResetPasswordRequest](), handler.ResetPassword)
+	r.Post("/2fa/setup", middleware.Protected(), middleware.DenyImpersonation(), handler.SetupTwoFactor)
+	r.Post("/2fa/confirm", middleware.Protected(), middleware.DenyImpersonation(), middleware.Validate[dto.TwoFactorCodeRequest](), handler.ConfirmTwoFactor)
+	r.Post("/2fa/disable", middleware.Protected(), middleware.DenyImpersonation(), middleware.Validate[dto.TwoFactorCodeRequest](), handler.DisableTwoFactor)
+	r.Post("/2fa/verify", middleware.Validate[dto.TwoFactorVerifyRequest](), handler.VerifyTwoFactor)
+	r.Post("/impersonation/end", middleware.Protected(), handler.EndImpersonation)
 }
 
 // @Summary		Register a new user
@@ -46,7 +63,7 @@ func (h *httpHandler) Register(c *fiber.Ctx) error {
 }
 
 // @Summary		User login
-// @Description	User login with email and password
+// @Description	User login with email and password. When two-factor authentication is enabled, an MFA token is returned instead of the tokens.
 // @Tags			Auth
 // @Accept			application/json
 // @Produce		application/json
@@ -54,6 +71,9 @@ func (h *httpHandler) Register(c *fiber.Ctx) error {
 // @Success		200		{object}	dto.ResponseDto{data=dto.LoginResponse}
 // @Failure		400		{object}	dto.ResponseDto
 // @Failure		401		{object}	dto.ResponseDto
+// @Failure		403		{object}	dto.ResponseDto
+// @Failure		423		{object}	dto.ResponseDto
+// @Failure		429		{object}	dto.ResponseDto
 // @Failure		500		{object}	dto.ResponseDto
 // @Router			/auth/login [post]
 func (h *httpHandler) Login(c *fiber.Ctx) error {
@@ -62,8 +82,414 @@ func (h *httpHandler) Login(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	if data.MfaRequired {
+		return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+			Message: "Two-factor authentication required",
+			Data:    data,
+		})
+	}
 	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
 		Message: "Login successful",
 		Data:    data,
 	})
 }
+
+// @Summary		Request a login link
+// @Description	Email a single-use link for logging in without a password if the address is registered. Unless disabled, the link only works in the browser that requested it. Always responds with 202 unless too many links were requested for the address.
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Param			request	body		dto.MagicLinkRequest	true	"Magic link request"
+// @Success		202		{object}	dto.ResponseDto
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		429		{object}	dto.ResponseDto
+// @Router			/auth/magic-link [post]
+func (h *httpHandler) RequestMagicLink(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.MagicLinkRequest](c)
+	if err := h.authService.RequestMagicLink(c, req); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(dto.ResponseDto{
+		Message: "If the email is registered, a login link has been sent",
+	})
+}
+
+// @Summary		Log in with a login link
+// @Description	Exchange the token of a login link for the tokens of a session. When two-factor authentication is enabled, an MFA token is returned instead of the tokens.
+// @Tags			Auth
+// @Produce		application/json
+// @Param			token	query		string	true	"Login link token"
+// @Success		200		{object}	dto.ResponseDto{data=dto.LoginResponse}
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		403		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/auth/magic-link/callback [get]
+func (h *httpHandler) MagicLinkLogin(c *fiber.Ctx) error {
+	// The link is single-use, a stored response would hand out its tokens again
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	token := c.Query("token")
+	if token == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Missing login link token")
+	}
+	data, err := h.authService.MagicLinkLogin(c, token)
+	if err != nil {
+		return err
+	}
+	if data.MfaRequired {
+		return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+			Message: "Two-factor authentication required",
+			Data:    data,
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Login successful",
+		Data:    data,
+	})
+}
+
+// @Summary		Log in with an OAuth provider
+// @Description	Redirect to the provider to log in with the authorization code flow and PKCE. The provider redirects back to the callback.
+// @Tags			Auth
+// @Param			provider	path	string	true	"Provider name"
+// @Success		302
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		502	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/auth/oauth/{provider} [get]
+func (h *httpHandler) OAuthLogin(c *fiber.Ctx) error {
+	// Every redirect carries a fresh state and PKCE verifier
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	authURL, err := h.authService.OAuthLogin(c, c.Params("provider"))
+	if err != nil {
+		return err
+	}
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// @Summary		OAuth provider callback
+// @Description	Complete a login with an OAuth provider. The identity is linked to the user with the same verified email, or to a new user. When two-factor authentication is enabled, an MFA token is returned instead of the tokens.
+// @Tags			Auth
+// @Produce		application/json
+// @Param			provider			path		string	true	"Provider name"
+// @Param			code				query		string	false	"Authorization code"
+// @Param			state				query		string	true	"State"
+// @Param			error				query		string	false	"Error returned by the provider"
+// @Param			error_description	query		string	false	"Description of the error"
+// @Success		200					{object}	dto.ResponseDto{data=dto.LoginResponse}
+// @Failure		400					{object}	dto.ResponseDto
+// @Failure		401					{object}	dto.ResponseDto
+// @Failure		403					{object}	dto.ResponseDto
+// @Failure		404					{object}	dto.ResponseDto
+// @Failure		409					{object}	dto.ResponseDto
+// @Failure		422					{object}	dto.ResponseDto
+// @Failure		500					{object}	dto.ResponseDto
+// @Router			/auth/oauth/{provider}/callback [get]
+func (h *httpHandler) OAuthCallback(c *fiber.Ctx) error {
+	// The state is single-use, a stored response would hand out its tokens again
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	req := utils.ExtractStructFromValidator[dto.OAuthCallbackRequest](c)
+	data, err := h.authService.OAuthCallback(c, c.Params("provider"), req)
+	if err != nil {
+		return err
+	}
+	if data.MfaRequired {
+		return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+			Message: "Two-factor authentication required",
+			Data:    data,
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Login successful",
+		Data:    data,
+	})
+}
+
+// @Summary		Refresh tokens
+// @Description	Exchange a refresh token for a new access and refresh token pair. Reusing a rotated refresh token revokes its whole family. In cookie mode the refresh token cookie is used when the body has none, and the new tokens are only set in cookies.
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Param			request	body		dto.RefreshTokenRequest	true	"Refresh token request"
+// @Success		200		{object}	dto.ResponseDto{data=dto.LoginResponse}
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/auth/refresh [post]
+func (h *httpHandler) Refresh(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.RefreshTokenRequest](c)
+	data, err := h.authService.Refresh(c, req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Token refreshed successfully",
+		Data:    data,
+	})
+}
+
+// @Summary		Logout
+// @Description	Revoke the current access token and its refresh token family. In cookie mode the token cookies are deleted as well.
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Success		200	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/auth/logout [post]
+func (h *httpHandler) Logout(c *fiber.Ctx) error {
+	if err := h.authService.Logout(c); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Logout successful",
+	})
+}
+
+// @Summary		Logout from all devices
+// @Description	Revoke every access and refresh token issued to the current user
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Success		200	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/auth/logout-all [post]
+func (h *httpHandler) LogoutAll(c *fiber.Ctx) error {
+	if err := h.authService.LogoutAll(c); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Logged out from all devices",
+	})
+}
+
+// @Summary		Get the CSRF token
+// @Description	Get the CSRF token of the session in cookie mode. Frontends on another site cannot read the CSRF token cookie and use this after a reload instead.
+// @Tags			Auth
+// @Produce		application/json
+// @Success		200	{object}	dto.ResponseDto{data=dto.CSRFTokenResponse}
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Router			/auth/csrf [get]
+func (h *httpHandler) CSRFToken(c *fiber.Ctx) error {
+	data, err := h.authService.CSRFToken(c)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "CSRF token found",
+		Data:    data,
+	})
+}
+
+// @Summary		Verify email address
+// @Description	Verify the email address of a user with the token sent by email
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Param			token	query		string	true	"Verification token"
+// @Success		200		{object}	dto.ResponseDto
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/auth/verify [get]
+func (h *httpHandler) VerifyEmail(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Missing verification token")
+	}
+	if err := h.authService.VerifyEmail(c, token); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Email verified successfully",
+	})
+}
+
+// @Summary		Resend verification email
+// @Description	Send a new verification email if the address belongs to an unverified user
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Param			request	body		dto.ResendVerificationRequest	true	"Resend verification request"
+// @Success		202		{object}	dto.ResponseDto
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Router			/auth/verify/resend [post]
+func (h *httpHandler) ResendVerification(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.ResendVerificationRequest](c)
+	if err := h.authService.ResendVerification(c, req); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(dto.ResponseDto{
+		Message: "If the email is registered and not yet verified, a verification email has been sent",
+	})
+}
+
+// @Summary		Forgot password
+// @Description	Email a password reset link if the address is registered. Responds with 202 unless rate limited.
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Param			request	body		dto.ForgotPasswordRequest	true	"Forgot password request"
+// @Success		202		{object}	dto.ResponseDto
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		429		{object}	dto.ResponseDto
+// @Router			/auth/password/forgot [post]
+func (h *httpHandler) ForgotPassword(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.ForgotPasswordRequest](c)
+	if err := h.authService.ForgotPassword(c, req); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(dto.ResponseDto{
+		Message: "If the email is registered, a password reset link has been sent",
+	})
+}
+
+// @Summary		Reset password
+// @Description	Set a new password with a reset token and log out every session of the user
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Param			request	body		dto.ResetPasswordRequest	true	"Reset password request"
+// @Success		200		{object}	dto.ResponseDto
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/auth/password/reset [post]
+func (h *httpHandler) ResetPassword(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.ResetPasswordRequest](c)
+	if err := h.authService.ResetPassword(c, req); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Password reset successfully",
+	})
+}
+
+// @Summary		Set up two-factor authentication
+// @Description	Generate a TOTP secret for the current user. It is enforced once confirmed with a code.
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Success		200	{object}	dto.ResponseDto{data=dto.TwoFactorSetupResponse}
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		409	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/auth/2fa/setup [post]
+func (h *httpHandler) SetupTwoFactor(c *fiber.Ctx) error {
+	data, err := h.authService.SetupTwoFactor(c)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Scan the QR code with your authenticator app and confirm with a code",
+		Data:    data,
+	})
+}
+
+// @Summary		Confirm two-factor authentication
+// @Description	Enable two-factor authentication with a code from the authenticator app and get one-time recovery codes
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			request	body		dto.TwoFactorCodeRequest	true	"Two-factor code"
+// @Success		200		{object}	dto.ResponseDto{data=dto.TwoFactorConfirmResponse}
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		409		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/auth/2fa/confirm [post]
+func (h *httpHandler) ConfirmTwoFactor(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.TwoFactorCodeRequest](c)
+	data, err := h.authService.ConfirmTwoFactor(c, req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Two-factor authentication enabled",
+		Data:    data,
+	})
+}
+
+// @Summary		Disable two-factor authentication
+// @Description	Disable two-factor authentication with a code from the authenticator app
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			request	body		dto.TwoFactorCodeRequest	true	"Two-factor code"
+// @Success		200		{object}	dto.ResponseDto
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/auth/2fa/disable [post]
+func (h *httpHandler) DisableTwoFactor(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.TwoFactorCodeRequest](c)
+	if err := h.authService.DisableTwoFactor(c, req); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// @Summary		Verify two-factor login
+// @Description	Complete a login with the MFA token and either a TOTP code or a recovery code. Repeated invalid codes lock the account like failed logins do.
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Param			request	body		dto.TwoFactorVerifyRequest	true	"Two-factor verification request"
+// @Success		200		{object}	dto.ResponseDto{data=dto.LoginResponse}
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		423		{object}	dto.ResponseDto
+// @Failure		429		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/auth/2fa/verify [post]
+func (h *httpHandler) VerifyTwoFactor(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.TwoFactorVerifyRequest](c)
+	data, err := h.authService.VerifyTwoFactor(c, req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Login successful",
+		Data:    data,
+	})
+}
+
+// @Summary		End impersonation
+// @Description	Revoke the impersonation token the request is made with
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Success		200	{object}	dto.ResponseDto
+// @Failure		400	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/auth/impersonation/end [post]
+func (h *httpHandler) EndImpersonation(c *fiber.Ctx) error {
+	if err := h.authService.EndImpersonation(c); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Impersonation ended",
+	})
+}
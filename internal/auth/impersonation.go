@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/xjwt"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *service) Impersonate(c *fiber.Ctx, userID uint) (*dto.ImpersonationResponse, error) {
+	admin, err := s.currentUser(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if admin.ID == userID {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "You cannot impersonate yourself")
+	}
+
+	user, _ := s.userRepo.FindByID(userID)
+	if user == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+	if user.DisabledAt != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Disabled users cannot be impersonated")
+	}
+	// Acting as another administrator would let one administrator use the access of another
+	if slices.Contains(user.RoleNames(), constant.RoleAdmin) {
+		return nil, fiber.NewError(fiber.StatusForbidden, "Administrators cannot be impersonated")
+	}
+
+	// The token is not part of a session and gets no refresh token, so it ends on its own
+	lifetime := time.Duration(config.Config.Jwt.ImpersonationExpiredAt) * time.Second
+	expiresAt := time.Now().Add(lifetime)
+	accessToken, err := xjwt.GenerateToken(user, xjwt.TokenTypeAccess, xjwt.WithActor(admin), xjwt.WithLifetime(lifetime))
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(c, constant.AuditActionImpersonationStart, user.ID, nil)
+	log.Warn().
+		Uint("actor_id", admin.ID).
+		Str("actor_email", admin.Email).
+		Uint("user_id", user.ID).
+		Str("ip", c.IP()).
+		Msg("Impersonation started")
+
+	return &dto.ImpersonationResponse{
+		AccessToken: accessToken,
+		ExpiresAt:   expiresAt.Format("2006-01-02 15:04:05"),
+		UserID:      user.ID,
+	}, nil
+}
+
+func (s *service) EndImpersonation(c *fiber.Ctx) error {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+	if !claims.Impersonated() {
+		return fiber.NewError(fiber.StatusBadRequest, "The token is not an impersonation token")
+	}
+
+	if err := s.revocationStore.Revoke(claims.ID, claims.UserID(), claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+
+	s.audit(c, constant.AuditActionImpersonationEnd, claims.UserID(), nil)
+	log.Info().
+		Uint("actor_id", claims.ActorID()).
+		Uint("user_id", claims.UserID()).
+		Msg("Impersonation ended")
+
+	return nil
+}
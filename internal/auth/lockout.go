@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// twoFactorThrottleKey is separate from the account key, which a correct password clears, so
+// that guessing second factors locks the account even when the password is known.
+func twoFactorThrottleKey(userID uint) string {
+	return "two_factor:" + strconv.Itoa(int(userID))
+}
+
+func isIPThrottleKey(key string) bool {
+	return strings.HasPrefix(key, "ip:")
+}
+
+func (s *service) Unlock(c *fiber.Ctx, userID uint) error {
+	user, _ := s.userRepo.FindByID(userID)
+	if user == nil {
+		return fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	if err := s.clearThrottle(accountThrottleKey(user.Email), twoFactorThrottleKey(user.ID)); err != nil {
+		return err
+	}
+
+	s.audit(c, constant.AuditActionUserUnlocked, user.ID, nil)
+	return nil
+}
+
+// checkThrottle refuses the login while the key is locked or still backing off from its last failure.
+func (s *service) checkThrottle(c *fiber.Ctx, key string) error {
+	attempt, err := s.loginAttemptRepo.FindByKey(key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+		setRetryAfter(c, attempt.LockedUntil.Sub(now))
+		if isIPThrottleKey(key) {
+			return fiber.NewError(fiber.StatusTooManyRequests, "Too many failed login attempts from your network, try again later")
+		}
+		return fiber.NewError(fiber.StatusLocked, "Account is temporarily locked due to too many failed login attempts")
+	}
+
+	if isStale(attempt, now) {
+		return nil
+	}
+
+	if retryAt := attempt.LastFailureAt.Add(backoff(attempt.Failures)); now.Before(retryAt) {
+		setRetryAfter(c, retryAt.Sub(now))
+		return fiber.NewError(fiber.StatusTooManyRequests, "Too many failed login attempts, try again later")
+	}
+
+	return nil
+}
+
+// recordFailure counts a failed login against the key and locks the key once its threshold is
+// reached. The owner of the account, if any, is notified about the lock.
+func (s *service) recordFailure(c *fiber.Ctx, key string, user *entity.User) error {
+	now := time.Now()
+	attempt, err := s.loginAttemptRepo.Increment(key, now.Add(-lockoutDuration()), now)
+	if err != nil {
+		return err
+	}
+	if attempt.Failures < lockoutThreshold(key) {
+		return nil
+	}
+
+	// Concurrent failures can all reach the threshold, only the one that locks the key reports it
+	lockedUntil := now.Add(lockoutDuration())
+	locked, err := s.loginAttemptRepo.Lock(attempt.ID, lockedUntil, now)
+	if err != nil || !locked {
+		return err
+	}
+
+	if user == nil || isIPThrottleKey(key) {
+		log.Warn().Str("throttle_key", key).Msg("Throttle key locked after too many failed login attempts")
+		return nil
+	}
+
+	s.auditService.Record(c, &interfaces.AuditRecord{
+		Action:     constant.AuditActionAccountLocked,
+		TargetType: constant.AuditTargetUser,
+		TargetID:   strconv.Itoa(int(user.ID)),
+		Diff:       map[string]string{"locked_until": lockedUntil.Format("2006-01-02 15:04:05")},
+	})
+	log.Warn().Uint("user_id", user.ID).Str("ip", c.IP()).Msg("Account locked after too many failed login attempts")
+	if err := s.publishLockout(c, user, lockedUntil); err != nil {
+		log.Error().Err(err).Uint("user_id", user.ID).Msg("Failed to publish lockout event")
+	}
+
+	return nil
+}
+
+// rateLimit counts a request against the key. Requests are counted like failed logins, and the key
+// is locked for the window once the limit is reached, refusing further requests with the message.
+func (s *service) rateLimit(c *fiber.Ctx, key string, limit int, window time.Duration, message string) error {
+	now := time.Now()
+
+	// Refused requests are not counted, so that the count starts over once the lock has expired
+	attempt, err := s.loginAttemptRepo.FindByKey(key)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if attempt != nil && attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+		setRetryAfter(c, attempt.LockedUntil.Sub(now))
+		return fiber.NewError(fiber.StatusTooManyRequests, message)
+	}
+
+	attempt, err = s.loginAttemptRepo.Increment(key, now.Add(-window), now)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case attempt.Failures > limit:
+		// Concurrent requests counted past the limit before it was locked
+		setRetryAfter(c, window)
+		return fiber.NewError(fiber.StatusTooManyRequests, message)
+	case attempt.Failures == limit:
+		if _, err := s.loginAttemptRepo.Lock(attempt.ID, now.Add(window), now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clearThrottle forgets the failures counted against the keys after a successful login.
+func (s *service) clearThrottle(keys ...string) error {
+	for _, key := range keys {
+		if err := s.loginAttemptRepo.DeleteByKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) publishLockout(c *fiber.Ctx, user *entity.User, lockedUntil time.Time) error {
+	event, err := json.Marshal(&interfaces.LockoutEvent{
+		UserID:      user.ID,
+		Name:        user.Name,
+		Email:       user.Email,
+		IP:          c.IP(),
+		LockedUntil: lockedUntil,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.kafkaClient.Produce(c.Context(), "auth.lockout", event)
+}
+
+// isStale reports whether the failures of the attempt are old enough to be forgotten.
+func isStale(attempt *entity.LoginAttempt, now time.Time) bool {
+	if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+		return false
+	}
+	return now.Sub(attempt.LastFailureAt) > lockoutDuration()
+}
+
+// backoff returns how long to wait after the given number of consecutive failures.
+func backoff(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+
+	seconds := float64(config.Config.Auth.BackoffBase) * math.Pow(2, float64(failures-1))
+	seconds = math.Min(seconds, float64(config.Config.Auth.BackoffMax))
+
+	return time.Duration(seconds) * time.Second
+}
+
+// lockoutThreshold returns the number of failures after which the key is locked.
+func lockoutThreshold(key string) int {
+	if isIPThrottleKey(key) {
+		return config.Config.Auth.IPLockoutThreshold
+	}
+	return config.Config.Auth.LockoutThreshold
+}
+
+func lockoutDuration() time.Duration {
+	return time.Duration(config.Config.Auth.LockoutDuration) * time.Second
+}
+
+func setRetryAfter(c *fiber.Ctx, d time.Duration) {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(d.Seconds()))))
+}
@@ -0,0 +1,123 @@
+package auth_test
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestLoginKeepsTheFailuresOfTheNetwork(t *testing.T) {
+	at := newAuthTest(t)
+	at.createUser(t, "user@example.com", "Password1!")
+
+	resp := at.post(t, "/api/v1/auth/login", dto.LoginRequest{Email: "user@example.com", Password: "Password2!"}, nil)
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("login status = %d, want %d", resp.StatusCode, fiber.StatusUnauthorized)
+	}
+	at.passwordLogin(t, "user@example.com", "Password1!")
+
+	var attempts []entity.LoginAttempt
+	if err := at.db.Find(&attempts).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 1 || attempts[0].ThrottleKey != "ip:0.0.0.0" || attempts[0].Failures != 1 {
+		t.Errorf("throttle keys after a successful login = %+v, want only the failure of the IP", attempts)
+	}
+}
+
+func TestLoginLocksTheAccountAfterTooManyFailures(t *testing.T) {
+	at := newAuthTest(t)
+	at.createUser(t, "user@example.com", "Password1!")
+
+	for i := range 5 {
+		resp := at.post(t, "/api/v1/auth/login", dto.LoginRequest{Email: "user@example.com", Password: "Password2!"}, nil)
+		if resp.StatusCode != fiber.StatusUnauthorized {
+			t.Fatalf("attempt %d: status = %d, want %d", i+1, resp.StatusCode, fiber.StatusUnauthorized)
+		}
+	}
+
+	// Even the right password is refused while the account is locked
+	resp := at.post(t, "/api/v1/auth/login", dto.LoginRequest{Email: "user@example.com", Password: "Password1!"}, nil)
+	if resp.StatusCode != fiber.StatusLocked {
+		t.Fatalf("status after too many failures = %d, want %d", resp.StatusCode, fiber.StatusLocked)
+	}
+	if retryAfter, _ := strconv.Atoi(resp.Header.Get(fiber.HeaderRetryAfter)); retryAfter < 890 || retryAfter > 900 {
+		t.Errorf("Retry-After = %d, want the 900 seconds of the lockout", retryAfter)
+	}
+
+	var audited int64
+	if err := at.db.Model(&entity.AuditEvent{}).Where("action = ?", constant.AuditActionAccountLocked).Count(&audited).Error; err != nil {
+		t.Fatal(err)
+	}
+	if audited != 1 {
+		t.Errorf("%d lock events audited, want 1", audited)
+	}
+}
+
+func TestLoginBacksOffAfterAFailure(t *testing.T) {
+	at := newAuthTest(t)
+	config.Config.Auth.BackoffBase = 2
+	config.Config.Auth.BackoffMax = 60
+	at.createUser(t, "user@example.com", "Password1!")
+
+	login := dto.LoginRequest{Email: "user@example.com", Password: "Password1!"}
+	resp := at.post(t, "/api/v1/auth/login", dto.LoginRequest{Email: "user@example.com", Password: "Password2!"}, nil)
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("failed login status = %d, want %d", resp.StatusCode, fiber.StatusUnauthorized)
+	}
+
+	resp = at.post(t, "/api/v1/auth/login", login, nil)
+	if resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("login during the backoff: status = %d, want %d", resp.StatusCode, fiber.StatusTooManyRequests)
+	}
+	if retryAfter := resp.Header.Get(fiber.HeaderRetryAfter); retryAfter != "1" && retryAfter != "2" {
+		t.Errorf("Retry-After = %q, want at most the 2 seconds of the first backoff", retryAfter)
+	}
+
+	// Refused attempts do not count as failures, so the backoff ends on time
+	past := time.Now().Add(-3 * time.Second)
+	if err := at.db.Model(&entity.LoginAttempt{}).Where("1 = 1").Update("last_failure_at", past).Error; err != nil {
+		t.Fatal(err)
+	}
+	if resp := at.post(t, "/api/v1/auth/login", login, nil); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("login after the backoff: status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+}
+
+func TestTwoFactorConfirmationIsThrottled(t *testing.T) {
+	at := newAuthTest(t)
+	at.createUser(t, "user@example.com", "Password1!")
+	token := at.passwordLogin(t, "user@example.com", "Password1!").AccessToken
+
+	var setup dto.TwoFactorSetupResponse
+	if resp := at.postAs(t, token, "/api/v1/auth/2fa/setup", nil, &setup); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("setup status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+	code, err := at.totp.Code(setup.Secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := range 5 {
+		resp := at.postAs(t, token, "/api/v1/auth/2fa/confirm", dto.TwoFactorCodeRequest{Code: wrong}, nil)
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Fatalf("attempt %d: status = %d, want %d", i+1, resp.StatusCode, fiber.StatusBadRequest)
+		}
+	}
+
+	// Even the right code is refused once the second factor is locked
+	resp := at.postAs(t, token, "/api/v1/auth/2fa/confirm", dto.TwoFactorCodeRequest{Code: code}, nil)
+	if resp.StatusCode != fiber.StatusLocked {
+		t.Errorf("status after too many failures = %d, want %d", resp.StatusCode, fiber.StatusLocked)
+	}
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// magicLinkDeviceCookie holds a secret of the browser that requested a login link. Links only
+// work in the browser holding the secret they were issued for, so a leaked link is useless elsewhere.
+const magicLinkDeviceCookie = "magic_link_device"
+
+func magicLinkThrottleKey(email string) string {
+	return "magic_link:" + strings.ToLower(email)
+}
+
+func (s *service) RequestMagicLink(c *fiber.Ctx, req *dto.MagicLinkRequest) error {
+	// Throttled whether or not the email is registered, so that the limit gives nothing away
+	limit, window := config.Config.Auth.MagicLinkRateLimit, time.Duration(config.Config.Auth.MagicLinkRateWindow)*time.Second
+	if err := s.rateLimit(c, magicLinkThrottleKey(req.Email), limit, window, "Too many login links requested, try again later"); err != nil {
+		return err
+	}
+
+	deviceHash, err := s.deviceHash(c)
+	if err != nil {
+		return err
+	}
+
+	// Always succeed so that the endpoint cannot be used to find out which emails are registered
+	user, _ := s.userRepo.FindByEmail(req.Email)
+	if user == nil {
+		user = s.findRestorable(req.Email)
+	}
+	if user == nil || user.DisabledAt != nil {
+		return nil
+	}
+
+	if err := s.sendMagicLinkEmail(c, user, deviceHash); err != nil {
+		log.Error().Err(err).Uint("user_id", user.ID).Msg("Failed to send magic link email")
+	}
+
+	return nil
+}
+
+func (s *service) MagicLinkLogin(c *fiber.Ctx, token string) (*dto.LoginResponse, error) {
+	claims, err := xjwt.ParseToken(token, xjwt.TokenTypeMagicLink)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid or expired login link")
+	}
+
+	if claims.Device != "" {
+		secret := c.Cookies(magicLinkDeviceCookie)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(utils.HashToken(secret)), []byte(claims.Device)) != 1 {
+			return nil, fiber.NewError(fiber.StatusForbidden, "Open the login link in the browser it was requested from")
+		}
+	}
+
+	// Login links are single-use: a used link is put on the revocation list. This also refuses
+	// links issued before the user logged out everywhere.
+	revoked, err := s.revocationStore.IsRevoked(claims.ID, claims.UserID(), claims.IssuedAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid or expired login link")
+	}
+
+	user, _ := s.userRepo.FindByID(claims.UserID())
+	if user == nil {
+		user = s.findRestorable(claims.UserEmail)
+	}
+	// The link was sent to an address the user no longer has
+	if user == nil || user.ID != claims.UserID() || user.Email != claims.UserEmail {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid or expired login link")
+	}
+
+	// Checked again on consuming the link, as the same link may be opened twice at once
+	consumed, err := s.revocationStore.Consume(claims.ID, user.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid or expired login link")
+	}
+
+	if err := s.admit(user); err != nil {
+		return nil, err
+	}
+
+	// Opening the link proves that the user owns the address
+	if user.VerifiedAt == nil {
+		now := time.Now()
+		user.VerifiedAt = &now
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.completeLogin(c, user)
+}
+
+// deviceHash returns the hash of the secret of the requesting browser, handing it a new secret
+// if it has none yet. It returns an empty hash when links are not bound to devices.
+func (s *service) deviceHash(c *fiber.Ctx) (string, error) {
+	if !config.Config.Auth.MagicLinkBindDevice {
+		return "", nil
+	}
+
+	// Keep the secret of earlier requests so that their links keep working
+	secret := c.Cookies(magicLinkDeviceCookie)
+	if secret == "" {
+		var err error
+		secret, err = utils.GenerateRandomToken(32)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     magicLinkDeviceCookie,
+		Value:    secret,
+		Path:     "/api/v1/auth/magic-link",
+		MaxAge:   int(xjwt.TTL(xjwt.TokenTypeMagicLink).Seconds()),
+		Secure:   strings.HasPrefix(config.Config.BaseURL, "https://"),
+		HTTPOnly: true,
+		// Lax lets the cookie through when the link is opened from an email
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return utils.HashToken(secret), nil
+}
+
+func (s *service) sendMagicLinkEmail(c *fiber.Ctx, user *entity.User, deviceHash string) error {
+	token, err := xjwt.GenerateToken(user, xjwt.TokenTypeMagicLink, xjwt.WithDevice(deviceHash))
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/v1/auth/magic-link/callback?token=%s", config.Config.BaseURL, url.QueryEscape(token))
+	emailConfig := &interfaces.EmailConfig{
+		To:      user.Email,
+		Subject: "Your login link",
+		Body: fmt.Sprintf(
+			"Hello %s, you can log in to your account by opening this link within %d minutes: %s. If you did not request this, you can ignore this email.",
+			user.Name, int(xjwt.TTL(xjwt.TokenTypeMagicLink).Minutes()), link,
+		),
+	}
+
+	return s.publishEmail(c, "auth.magic_link", emailConfig)
+}
@@ -0,0 +1,52 @@
+package auth_test
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/lib/xjwt"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMagicLinkIsSingleUse(t *testing.T) {
+	at := newAuthTest(t)
+
+	u := at.createUser(t, "user@example.com", "Password1!")
+	token, err := xjwt.GenerateToken(u, xjwt.TokenTypeMagicLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/v1/auth/magic-link/callback?token=" + url.QueryEscape(token)
+
+	var data dto.LoginResponse
+	resp := at.send(t, httptest.NewRequest(fiber.MethodGet, path, nil), nil, &data)
+	if resp.StatusCode != fiber.StatusOK || data.AccessToken == "" {
+		t.Fatalf("first use: status = %d, tokens = %+v", resp.StatusCode, data)
+	}
+	if resp.Header.Get(fiber.HeaderCacheControl) != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", resp.Header.Get(fiber.HeaderCacheControl))
+	}
+
+	// Neither the server nor its cache may hand out the tokens again
+	resp = at.send(t, httptest.NewRequest(fiber.MethodGet, path, nil), nil, nil)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("second use: status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+}
+
+func TestMagicLinkRequestsAreThrottled(t *testing.T) {
+	at := newAuthTest(t)
+
+	for i := range 6 {
+		resp := at.post(t, "/api/v1/auth/magic-link", dto.MagicLinkRequest{Email: "user@example.com"}, nil)
+		want := fiber.StatusAccepted
+		if i == 5 {
+			want = fiber.StatusTooManyRequests
+		}
+		if resp.StatusCode != want {
+			t.Fatalf("request %d: status = %d, want %d", i+1, resp.StatusCode, want)
+		}
+	}
+}
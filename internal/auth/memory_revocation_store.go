@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"go-fiber-template/internal/domain/interfaces"
+	"sync"
+	"time"
+)
+
+// memoryRevocationStore is an in-process TokenRevocationStore for tests and single-instance setups.
+type memoryRevocationStore struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[uint]memoryUserRevocation
+}
+
+type memoryUserRevocation struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+func (s *memoryRevocationStore) Revoke(tokenID string, userID uint, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[tokenID] = expiresAt
+	return nil
+}
+
+func (s *memoryRevocationStore) Consume(tokenID string, userID uint, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tokens[tokenID]; ok {
+		return false, nil
+	}
+	s.tokens[tokenID] = expiresAt
+	return true, nil
+}
+
+func (s *memoryRevocationStore) RevokeUser(userID uint, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[userID] = memoryUserRevocation{
+		revokedAt: revocationCutoff(time.Now()),
+		expiresAt: expiresAt,
+	}
+	return nil
+}
+
+func (s *memoryRevocationStore) IsRevoked(tokenID string, userID uint, issuedAt time.Time) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.tokens[tokenID]; ok {
+		return true, nil
+	}
+	if revocation, ok := s.users[userID]; ok && !revocation.revokedAt.Before(issuedAt) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (s *memoryRevocationStore) PurgeExpired() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for tokenID, expiresAt := range s.tokens {
+		if expiresAt.Before(now) {
+			delete(s.tokens, tokenID)
+		}
+	}
+	for userID, revocation := range s.users {
+		if revocation.expiresAt.Before(now) {
+			delete(s.users, userID)
+		}
+	}
+
+	return nil
+}
+
+func NewMemoryRevocationStore() interfaces.TokenRevocationStore {
+	return &memoryRevocationStore{
+		tokens: make(map[string]time.Time),
+		users:  make(map[uint]memoryUserRevocation),
+	}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/xjwt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *service) Disable(c *fiber.Ctx, userID uint) error {
+	if claims := xjwt.ExtractTokenFromCtx(c); claims != nil && claims.UserID() == userID {
+		return fiber.NewError(fiber.StatusBadRequest, "You cannot disable your own account")
+	}
+
+	user, _ := s.userRepo.FindByID(userID)
+	if user == nil {
+		return fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	if user.DisabledAt == nil {
+		now := time.Now()
+		user.DisabledAt = &now
+		if err := s.userRepo.Update(user); err != nil {
+			return err
+		}
+		s.audit(c, constant.AuditActionUserDisabled, user.ID, nil)
+	}
+
+	log.Info().Uint("user_id", user.ID).Msg("User disabled")
+	return s.revokeAllTokens(user.ID)
+}
+
+func (s *service) Enable(c *fiber.Ctx, userID uint) error {
+	user, _ := s.userRepo.FindByID(userID)
+	if user == nil {
+		return fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	if user.DisabledAt == nil {
+		return nil
+	}
+
+	user.DisabledAt = nil
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	s.audit(c, constant.AuditActionUserEnabled, user.ID, nil)
+	log.Info().Uint("user_id", user.ID).Msg("User enabled")
+	return nil
+}
+
+func (s *service) ForceLogout(c *fiber.Ctx, userID uint) error {
+	user, _ := s.userRepo.FindByID(userID)
+	if user == nil {
+		return fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	if err := s.revokeAllTokens(user.ID); err != nil {
+		return err
+	}
+
+	s.audit(c, constant.AuditActionUserForcedLogout, user.ID, nil)
+	return nil
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xoidc"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// oauthStateCookie binds an authorization request to the browser that started it, so that
+// nobody can log a victim in to their own account by making them open a callback URL.
+const oauthStateCookie = "oauth_state"
+
+func (s *service) OAuthLogin(c *fiber.Ctx, provider string) (string, error) {
+	p, ok := s.oauthProviders[provider]
+	if !ok {
+		return "", fiber.NewError(fiber.StatusNotFound, "OAuth provider not found")
+	}
+
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := p.AuthCodeURL(c.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Error().Err(err).Str("provider", provider).Msg("Failed to reach OAuth provider")
+		return "", fiber.NewError(fiber.StatusBadGateway, "OAuth provider is unavailable")
+	}
+
+	lifetime := time.Duration(config.Config.OAuth.StateExpiredAt) * time.Second
+	if err := s.oauthStateRepo.Create(&entity.OAuthState{
+		StateHash:    utils.HashToken(state),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(lifetime),
+	}); err != nil {
+		return "", err
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/api/v1/auth/oauth",
+		MaxAge:   int(lifetime.Seconds()),
+		Secure:   strings.HasPrefix(config.Config.BaseURL, "https://"),
+		HTTPOnly: true,
+		// Lax lets the cookie through on the redirect back from the provider
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return authURL, nil
+}
+
+func (s *service) OAuthCallback(c *fiber.Ctx, provider string, req *dto.OAuthCallbackRequest) (*dto.LoginResponse, error) {
+	p, ok := s.oauthProviders[provider]
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusNotFound, "OAuth provider not found")
+	}
+
+	cookie := c.Cookies(oauthStateCookie)
+	c.ClearCookie(oauthStateCookie)
+	if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(req.State)) != 1 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid or expired OAuth state")
+	}
+
+	state, err := s.oauthStateRepo.Consume(utils.HashToken(req.State))
+	if err != nil || state.Provider != provider || state.ExpiresAt.Before(time.Now()) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid or expired OAuth state")
+	}
+
+	if req.Error != "" || req.Code == "" {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Login with the OAuth provider was cancelled or refused")
+	}
+
+	token, err := p.Exchange(c.Context(), req.Code, state.CodeVerifier)
+	if err != nil {
+		log.Warn().Err(err).Str("provider", provider).Msg("Failed to exchange OAuth authorization code")
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Failed to log in with the OAuth provider")
+	}
+
+	identity, err := p.Identity(c.Context(), token, state.Nonce)
+	if err != nil {
+		log.Warn().Err(err).Str("provider", provider).Msg("Failed to verify OAuth identity")
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Failed to log in with the OAuth provider")
+	}
+
+	user, err := s.findOrLinkUser(provider, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.admit(user); err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(c, user)
+}
+
+// findOrLinkUser returns the user the identity is linked to. Unlinked identities are linked to the
+// user with the same email, or to a new user, as long as the provider verified the email.
+func (s *service) findOrLinkUser(provider string, identity *xoidc.Identity) (*entity.User, error) {
+	link, err := s.identityRepo.FindByProviderSubject(provider, identity.Subject)
+	if err == nil {
+		user, _ := s.userRepo.FindByID(link.UserID)
+		if user == nil {
+			// Users deleted during the grace period can restore their account this way as well
+			user = s.findRestorable(identity.Email)
+		}
+		if user == nil || user.ID != link.UserID {
+			return nil, fiber.NewError(fiber.StatusUnauthorized, "Failed to log in with the OAuth provider")
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, fiber.NewError(fiber.StatusForbidden, "The OAuth provider did not confirm your email address")
+	}
+
+	user, _ := s.userRepo.FindByEmail(identity.Email)
+	if user == nil {
+		user = s.findRestorable(identity.Email)
+	}
+
+	if user == nil {
+		user, err = s.createOAuthUser(identity)
+		if err != nil {
+			return nil, err
+		}
+	} else if user.VerifiedAt == nil {
+		// Whoever registered the address without verifying it may not own it, linking would hand
+		// them the account of the real owner
+		return nil, fiber.NewError(fiber.StatusConflict, "An account with this email address exists but is not verified, log in with your password and verify it first")
+	}
+
+	if err := s.identityRepo.Create(&entity.ExternalIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}); err != nil {
+		return nil, err
+	}
+
+	log.Info().Uint("user_id", user.ID).Str("provider", provider).Msg("Linked external identity to user")
+
+	return user, nil
+}
+
+// createOAuthUser registers a user whose email was verified by the provider. The user has no
+// password until they set one through the password reset flow.
+func (s *service) createOAuthUser(identity *xoidc.Identity) (*entity.User, error) {
+	now := time.Now()
+	user := &entity.User{
+		Name:       identity.Name,
+		Email:      identity.Email,
+		VerifiedAt: &now,
+	}
+	if user.Name == "" {
+		user.Name, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	if err := s.validateUnique(user); err != nil {
+		return nil, err
+	}
+
+	defaultRole, err := s.roleRepo.FindByName(config.Config.Auth.DefaultRole)
+	if err != nil {
+		return nil, err
+	}
+	user.Roles = []entity.Role{*defaultRole}
+
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
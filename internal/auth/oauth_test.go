@@ -0,0 +1,282 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/lib/xoidc/xoidctest"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// login starts a login, lets the user log in at the provider and sends the callback. tamper can
+// change the callback and the state cookie before they are sent.
+func (at *authTest) login(t *testing.T, login xoidctest.Login, tamper func(callback *url.URL, cookie *http.Cookie)) (int, *dto.LoginResponse) {
+	t.Helper()
+
+	resp, err := at.app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/auth/oauth/fake", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusFound {
+		t.Fatalf("login status = %d, want %d", resp.StatusCode, fiber.StatusFound)
+	}
+
+	var cookie *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == "oauth_state" {
+			cookie = c
+		}
+	}
+	if cookie == nil {
+		t.Fatal("login set no state cookie")
+	}
+
+	redirect, err := at.provider.Authorize(resp.Header.Get(fiber.HeaderLocation), login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback, _ := url.Parse(redirect)
+	if tamper != nil {
+		tamper(callback, cookie)
+	}
+
+	req := httptest.NewRequest(fiber.MethodGet, callback.RequestURI(), nil)
+	req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	resp, err = at.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var body struct {
+		Data *dto.LoginResponse `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.StatusCode, body.Data
+}
+
+func (at *authTest) identity(t *testing.T, subject string) *entity.ExternalIdentity {
+	t.Helper()
+
+	var identity entity.ExternalIdentity
+	if err := at.db.Where("provider = ? AND subject = ?", "fake", subject).First(&identity).Error; err != nil {
+		t.Fatalf("identity %q was not linked: %v", subject, err)
+	}
+	return &identity
+}
+
+func TestOAuthCallbackCreatesUser(t *testing.T) {
+	at := newAuthTest(t)
+
+	status, data := at.login(t, xoidctest.Login{
+		Subject:       "new",
+		Email:         "new@example.com",
+		EmailVerified: true,
+		Name:          "New User",
+	}, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("callback status = %d, want %d", status, fiber.StatusOK)
+	}
+	if data == nil || data.AccessToken == "" {
+		t.Fatal("callback returned no tokens")
+	}
+
+	var created entity.User
+	if err := at.db.Where("email = ?", "new@example.com").First(&created).Error; err != nil {
+		t.Fatal(err)
+	}
+	if created.Name != "New User" || created.VerifiedAt == nil {
+		t.Errorf("created user = %+v, want a verified user named after the identity", created)
+	}
+	if identity := at.identity(t, "new"); identity.UserID != created.ID {
+		t.Errorf("identity linked to user %d, want %d", identity.UserID, created.ID)
+	}
+
+	// Logging in again uses the link instead of the email, which may have changed at the provider
+	status, _ = at.login(t, xoidctest.Login{Subject: "new", Email: "changed@example.com"}, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("second callback status = %d, want %d", status, fiber.StatusOK)
+	}
+	var count int64
+	at.db.Model(&entity.User{}).Count(&count)
+	if count != 1 {
+		t.Errorf("users = %d, want 1", count)
+	}
+}
+
+func TestOAuthCallbackLinksExistingUser(t *testing.T) {
+	at := newAuthTest(t)
+
+	now := time.Now()
+	existing := &entity.User{Name: "Existing", Email: "existing@example.com", Password: "hash", VerifiedAt: &now}
+	if err := at.db.Create(existing).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	status, _ := at.login(t, xoidctest.Login{
+		Subject:       "existing",
+		Email:         "existing@example.com",
+		EmailVerified: true,
+	}, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("callback status = %d, want %d", status, fiber.StatusOK)
+	}
+	if identity := at.identity(t, "existing"); identity.UserID != existing.ID {
+		t.Errorf("identity linked to user %d, want %d", identity.UserID, existing.ID)
+	}
+}
+
+func TestOAuthCallbackRejectsUnverifiedLink(t *testing.T) {
+	at := newAuthTest(t)
+
+	// The address was registered but never verified, so whoever registered it may not own it
+	if err := at.db.Create(&entity.User{Name: "Squatter", Email: "victim@example.com", Password: "hash"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	status, _ := at.login(t, xoidctest.Login{Subject: "victim", Email: "victim@example.com", EmailVerified: true}, nil)
+	if status != fiber.StatusConflict {
+		t.Errorf("callback status = %d, want %d", status, fiber.StatusConflict)
+	}
+
+	// Nor is an address the provider did not verify linked
+	status, _ = at.login(t, xoidctest.Login{Subject: "unverified", Email: "unverified@example.com"}, nil)
+	if status != fiber.StatusForbidden {
+		t.Errorf("callback status = %d, want %d", status, fiber.StatusForbidden)
+	}
+}
+
+func TestOAuthCallbackRejectsInvalidLogins(t *testing.T) {
+	tests := []struct {
+		name   string
+		login  xoidctest.Login
+		tamper func(callback *url.URL, cookie *http.Cookie)
+		status int
+	}{
+		{
+			name: "state mismatch",
+			tamper: func(callback *url.URL, _ *http.Cookie) {
+				query := callback.Query()
+				query.Set("state", "forged")
+				callback.RawQuery = query.Encode()
+			},
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name: "state of another browser",
+			tamper: func(_ *url.URL, cookie *http.Cookie) {
+				cookie.Value = "forged"
+			},
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name: "wrong code",
+			tamper: func(callback *url.URL, _ *http.Cookie) {
+				query := callback.Query()
+				query.Set("code", "forged")
+				callback.RawQuery = query.Encode()
+			},
+			status: fiber.StatusUnauthorized,
+		},
+		{
+			name:   "nonce mismatch",
+			login:  xoidctest.Login{Claims: map[string]any{"nonce": "other"}},
+			status: fiber.StatusUnauthorized,
+		},
+		{
+			name:   "forged ID token",
+			login:  xoidctest.Login{Forged: true},
+			status: fiber.StatusUnauthorized,
+		},
+		{
+			name:   "wrong audience",
+			login:  xoidctest.Login{Claims: map[string]any{"aud": "other client"}},
+			status: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := newAuthTest(t)
+
+			tt.login.Subject = "subject"
+			tt.login.Email = "user@example.com"
+			tt.login.EmailVerified = true
+			status, _ := at.login(t, tt.login, tt.tamper)
+			if status != tt.status {
+				t.Errorf("callback status = %d, want %d", status, tt.status)
+			}
+
+			var count int64
+			at.db.Model(&entity.User{}).Count(&count)
+			if count != 0 {
+				t.Errorf("users = %d, want none", count)
+			}
+		})
+	}
+}
+
+func TestOAuthCallbackStateIsSingleUse(t *testing.T) {
+	at := newAuthTest(t)
+
+	var replay url.URL
+	var cookie http.Cookie
+	status, _ := at.login(t, xoidctest.Login{Subject: "subject", Email: "user@example.com", EmailVerified: true}, func(callback *url.URL, c *http.Cookie) {
+		replay, cookie = *callback, *c
+	})
+	if status != fiber.StatusOK {
+		t.Fatalf("callback status = %d, want %d", status, fiber.StatusOK)
+	}
+
+	req := httptest.NewRequest(fiber.MethodGet, replay.RequestURI(), nil)
+	req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	resp, err := at.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("replayed callback status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+	if resp.Header.Get(fiber.HeaderCacheControl) != "no-store" {
+		t.Errorf("callback Cache-Control = %q, want no-store", resp.Header.Get(fiber.HeaderCacheControl))
+	}
+}
+
+func TestOAuthLoginStartsAFreshFlowEachTime(t *testing.T) {
+	at := newAuthTest(t)
+
+	seen := map[string]bool{}
+	for range 2 {
+		resp, err := at.app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/auth/oauth/fake", nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusFound {
+			t.Fatalf("login status = %d, want %d", resp.StatusCode, fiber.StatusFound)
+		}
+		if resp.Header.Get(fiber.HeaderCacheControl) != "no-store" {
+			t.Errorf("login Cache-Control = %q, want no-store", resp.Header.Get(fiber.HeaderCacheControl))
+		}
+
+		location := resp.Header.Get(fiber.HeaderLocation)
+		if seen[location] {
+			t.Errorf("login redirect %q was served twice", location)
+		}
+		seen[location] = true
+
+		var state bool
+		for _, c := range resp.Cookies() {
+			state = state || c.Name == "oauth_state"
+		}
+		if !state {
+			t.Error("login set no state cookie")
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"fmt"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xpassword"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+func passwordResetThrottleKey(email string) string {
+	return "password_reset:" + strings.ToLower(email)
+}
+
+func passwordResetIPThrottleKey(ip string) string {
+	return "password_reset_ip:" + ip
+}
+
+func (s *service) ForgotPassword(c *fiber.Ctx, req *dto.ForgotPasswordRequest) error {
+	// Throttled whether or not the email is registered, so that the limit gives nothing away.
+	// The address limit keeps a mailbox from being flooded, the network one spreading requests over addresses.
+	cfg := config.Config.Auth
+	window := time.Duration(cfg.PasswordResetRateWindow) * time.Second
+	if err := s.rateLimit(c, passwordResetIPThrottleKey(c.IP()), cfg.PasswordResetIPRateLimit, window, "Too many password resets requested from your network, try again later"); err != nil {
+		return err
+	}
+	if err := s.rateLimit(c, passwordResetThrottleKey(req.Email), cfg.PasswordResetRateLimit, window, "Too many password resets requested, try again later"); err != nil {
+		return err
+	}
+
+	// Always succeed so that the endpoint cannot be used to find out which emails are registered
+	user, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil {
+		return nil
+	}
+
+	if err := s.sendPasswordResetEmail(c, user); err != nil {
+		log.Error().Err(err).Uint("user_id", user.ID).Msg("Failed to send password reset email")
+	}
+
+	return nil
+}
+
+func (s *service) ResetPassword(c *fiber.Ctx, req *dto.ResetPasswordRequest) error {
+	token, err := s.resetTokenRepo.FindByTokenHash(utils.HashToken(req.Token))
+	if err != nil || token.UsedAt != nil || token.ExpiresAt.Before(time.Now()) {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid or expired password reset token")
+	}
+
+	user, err := s.userRepo.FindByID(token.UserID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid or expired password reset token")
+	}
+
+	// The request does not carry the name and email of the user, so check them here,
+	// before the token is used up
+	if err := xpassword.CheckPolicy(req.Password, user.Name, user.Email); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	fresh, err := s.resetTokenRepo.MarkUsed(token.ID)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid or expired password reset token")
+	}
+
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	if err := s.resetTokenRepo.InvalidateByUserID(user.ID); err != nil {
+		return err
+	}
+
+	if err := s.revokeAllTokens(user.ID); err != nil {
+		return err
+	}
+
+	s.auditSelf(c, constant.AuditActionPasswordReset, user.ID, nil)
+	return nil
+}
+
+func (s *service) sendPasswordResetEmail(c *fiber.Ctx, user *entity.User) error {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := s.resetTokenRepo.Create(&entity.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(time.Duration(config.Config.Auth.PasswordResetExpiredAt) * time.Second),
+	}); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s?token=%s", config.Config.Auth.PasswordResetURL, url.QueryEscape(token))
+	emailConfig := &interfaces.EmailConfig{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Hello %s, you can reset your password by opening this link: %s. If you did not request this, you can ignore this email.", user.Name, link),
+	}
+
+	return s.publishEmail(c, "auth.password_reset", emailConfig)
+}
@@ -0,0 +1,50 @@
+package auth_test
+
+import (
+	"fmt"
+	"go-fiber-template/internal/domain/dto"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestPasswordResetRequestsAreThrottledPerAddress(t *testing.T) {
+	at := newAuthTest(t)
+	at.createUser(t, "user@example.com", "Password1!")
+
+	for i := range 4 {
+		resp := at.post(t, "/api/v1/auth/password/forgot", dto.ForgotPasswordRequest{Email: "user@example.com"}, nil)
+		want := fiber.StatusAccepted
+		if i == 3 {
+			want = fiber.StatusTooManyRequests
+		}
+		if resp.StatusCode != want {
+			t.Fatalf("request %d: status = %d, want %d", i+1, resp.StatusCode, want)
+		}
+	}
+
+	// Other addresses are still served
+	resp := at.post(t, "/api/v1/auth/password/forgot", dto.ForgotPasswordRequest{Email: "other@example.com"}, nil)
+	if resp.StatusCode != fiber.StatusAccepted {
+		t.Errorf("other address: status = %d, want %d", resp.StatusCode, fiber.StatusAccepted)
+	}
+}
+
+func TestPasswordResetRequestsAreThrottledPerNetwork(t *testing.T) {
+	at := newAuthTest(t)
+
+	for i := range 6 {
+		email := fmt.Sprintf("user%d@example.com", i)
+		resp := at.post(t, "/api/v1/auth/password/forgot", dto.ForgotPasswordRequest{Email: email}, nil)
+		want := fiber.StatusAccepted
+		if i == 5 {
+			want = fiber.StatusTooManyRequests
+		}
+		if resp.StatusCode != want {
+			t.Fatalf("request %d: status = %d, want %d", i+1, resp.StatusCode, want)
+		}
+		if want == fiber.StatusTooManyRequests && resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+			t.Error("Retry-After is not set")
+		}
+	}
+}
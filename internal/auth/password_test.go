@@ -0,0 +1,34 @@
+package auth_test
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/lib/xpassword"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestPasswordsTooLongForBcryptAreRefused(t *testing.T) {
+	xpassword.Setup(xpassword.Config{Algorithm: xpassword.AlgorithmBcrypt, BcryptCost: 4})
+	xpassword.SetupPolicy(xpassword.PolicyConfig{MaxBytes: xpassword.BcryptMaxBytes})
+	t.Cleanup(func() {
+		xpassword.Setup()
+		xpassword.SetupPolicy()
+	})
+
+	at := newAuthTest(t)
+	at.createUser(t, "user@example.com", "Password1!")
+	tokens := at.passwordLogin(t, "user@example.com", "Password1!")
+
+	long := "Tr0ub4dor&3-" + strings.Repeat("x", 61)
+	resp := at.post(t, "/api/v1/auth/register", dto.RegisterRequest{Name: "Other", Email: "other@example.com", Password: long}, nil)
+	if resp.StatusCode != fiber.StatusUnprocessableEntity {
+		t.Errorf("register: status = %d, want %d", resp.StatusCode, fiber.StatusUnprocessableEntity)
+	}
+
+	resp = at.postAs(t, tokens.AccessToken, "/api/v1/users/me/password", dto.ChangePasswordRequest{CurrentPassword: "Password1!", NewPassword: long}, nil)
+	if resp.StatusCode != fiber.StatusUnprocessableEntity {
+		t.Errorf("change password: status = %d, want %d", resp.StatusCode, fiber.StatusUnprocessableEntity)
+	}
+}
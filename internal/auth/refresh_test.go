@@ -0,0 +1,77 @@
+package auth_test
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRefreshExtendsTheSession(t *testing.T) {
+	at := newAuthTest(t)
+	at.createUser(t, "user@example.com", "Password1!")
+	tokens := at.passwordLogin(t, "user@example.com", "Password1!")
+
+	soon := time.Now().Add(time.Minute)
+	if err := at.db.Model(&entity.Session{}).Where("1 = 1").Update("expires_at", soon).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	resp := at.post(t, "/api/v1/auth/refresh", dto.RefreshTokenRequest{RefreshToken: tokens.RefreshToken}, nil)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("refresh status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	var session entity.Session
+	if err := at.db.First(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+	// The refresh token lives for 600 seconds in the tests
+	if !session.ExpiresAt.After(time.Now().Add(500 * time.Second)) {
+		t.Errorf("session expires at %s after a refresh, want about 10 minutes from now", session.ExpiresAt)
+	}
+}
+
+func TestRefreshTokenReuseRevokesTheFamily(t *testing.T) {
+	at := newAuthTest(t)
+	at.createUser(t, "user@example.com", "Password1!")
+	stolen := at.passwordLogin(t, "user@example.com", "Password1!")
+	other := at.passwordLogin(t, "user@example.com", "Password1!")
+
+	var rotated dto.LoginResponse
+	resp := at.post(t, "/api/v1/auth/refresh", dto.RefreshTokenRequest{RefreshToken: stolen.RefreshToken}, &rotated)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("refresh status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	resp = at.post(t, "/api/v1/auth/refresh", dto.RefreshTokenRequest{RefreshToken: stolen.RefreshToken}, nil)
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("reuse status = %d, want %d", resp.StatusCode, fiber.StatusUnauthorized)
+	}
+
+	// The reuse kills the tokens rotated from the stolen one, but not the other logins
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"rotated token", rotated.RefreshToken, fiber.StatusUnauthorized},
+		{"other login", other.RefreshToken, fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		resp := at.post(t, "/api/v1/auth/refresh", dto.RefreshTokenRequest{RefreshToken: tt.token}, nil)
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s: refresh status = %d, want %d", tt.name, resp.StatusCode, tt.want)
+		}
+	}
+
+	var revoked int64
+	if err := at.db.Model(&entity.Session{}).Where("revoked_at IS NOT NULL").Count(&revoked).Error; err != nil {
+		t.Fatal(err)
+	}
+	if revoked != 1 {
+		t.Errorf("%d sessions revoked, want the one of the reused token", revoked)
+	}
+}
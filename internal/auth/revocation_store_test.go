@@ -0,0 +1,202 @@
+package auth_test
+
+import (
+	"go-fiber-template/internal/auth"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func revocationStores(t *testing.T) map[string]interfaces.TokenRevocationStore {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.RevokedToken{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]interfaces.TokenRevocationStore{
+		"memory": auth.NewMemoryRevocationStore(),
+		"sql":    auth.NewSQLRevocationStore(db),
+	}
+}
+
+func TestRevocationStoreRevokesTokens(t *testing.T) {
+	for name, store := range revocationStores(t) {
+		t.Run(name, func(t *testing.T) {
+			issuedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+			if err := store.Revoke("revoked", 1, time.Now().Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+
+			for tokenID, want := range map[string]bool{"revoked": true, "other": false} {
+				revoked, err := store.IsRevoked(tokenID, 1, issuedAt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if revoked != want {
+					t.Errorf("IsRevoked(%q) = %v, want %v", tokenID, revoked, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRevocationStoreRevokesEveryTokenOfTheUser(t *testing.T) {
+	for name, store := range revocationStores(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			if err := store.RevokeUser(1, now.Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+
+			// Issue times are whole seconds, like the iat claim
+			second := now.Truncate(time.Second)
+			tests := []struct {
+				name     string
+				userID   uint
+				issuedAt time.Time
+				want     bool
+			}{
+				{"issued before", 1, second.Add(-time.Second), true},
+				{"issued in the same second", 1, second, true},
+				{"issued in the next second", 1, second.Add(time.Second), false},
+				{"other user", 2, second.Add(-time.Second), false},
+			}
+			for _, tt := range tests {
+				revoked, err := store.IsRevoked("token", tt.userID, tt.issuedAt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if revoked != tt.want {
+					t.Errorf("%s: IsRevoked = %v, want %v", tt.name, revoked, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRevocationStoreConsumesTokensOnce(t *testing.T) {
+	for name, store := range revocationStores(t) {
+		t.Run(name, func(t *testing.T) {
+			for i, want := range []bool{true, false} {
+				consumed, err := store.Consume("token", 1, time.Now().Add(time.Hour))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if consumed != want {
+					t.Errorf("Consume #%d = %v, want %v", i+1, consumed, want)
+				}
+			}
+
+			revoked, err := store.IsRevoked("token", 1, time.Now().Truncate(time.Second))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !revoked {
+				t.Error("consumed token is not revoked")
+			}
+		})
+	}
+}
+
+func TestRevocationStorePurgesExpiredEntries(t *testing.T) {
+	for name, store := range revocationStores(t) {
+		t.Run(name, func(t *testing.T) {
+			issuedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+			if err := store.Revoke("expired", 1, time.Now().Add(-time.Second)); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.RevokeUser(1, time.Now().Add(-time.Second)); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Revoke("live", 2, time.Now().Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.PurgeExpired(); err != nil {
+				t.Fatal(err)
+			}
+
+			tests := []struct {
+				tokenID string
+				userID  uint
+				want    bool
+			}{
+				{"expired", 1, false},
+				{"live", 2, true},
+			}
+			for _, tt := range tests {
+				revoked, err := store.IsRevoked(tt.tokenID, tt.userID, issuedAt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if revoked != tt.want {
+					t.Errorf("IsRevoked(%q) after purge = %v, want %v", tt.tokenID, revoked, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLogoutAllRevokesAccessTokens(t *testing.T) {
+	at := newAuthTest(t)
+	at.createUser(t, "user@example.com", "Password1!")
+
+	var first, second dto.LoginResponse
+	for _, data := range []*dto.LoginResponse{&first, &second} {
+		resp := at.post(t, "/api/v1/auth/login", dto.LoginRequest{Email: "user@example.com", Password: "Password1!"}, data)
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("login status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+		}
+	}
+
+	req := httptest.NewRequest(fiber.MethodPost, "/api/v1/auth/logout-all", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+first.AccessToken)
+	if resp := at.send(t, req, nil, nil); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("logout-all status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	// Both tokens were issued in the second of the revocation at the latest
+	for _, token := range []string{first.AccessToken, second.AccessToken} {
+		req := httptest.NewRequest(fiber.MethodPost, "/api/v1/auth/logout", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		if resp := at.send(t, req, nil, nil); resp.StatusCode != fiber.StatusUnauthorized {
+			t.Errorf("logout with a revoked token: status = %d, want %d", resp.StatusCode, fiber.StatusUnauthorized)
+		}
+	}
+}
+
+func TestChangePasswordKeepsOnlyTheNewSession(t *testing.T) {
+	at := newAuthTest(t)
+	at.createUser(t, "user@example.com", "Password1!")
+
+	var old, fresh dto.LoginResponse
+	at.post(t, "/api/v1/auth/login", dto.LoginRequest{Email: "user@example.com", Password: "Password1!"}, &old)
+
+	req := httptest.NewRequest(fiber.MethodPost, "/api/v1/users/me/password", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+old.AccessToken)
+	resp := at.send(t, req, dto.ChangePasswordRequest{CurrentPassword: "Password1!", NewPassword: "Password2!"}, &fresh)
+	if resp.StatusCode != fiber.StatusOK || fresh.AccessToken == "" {
+		t.Fatalf("change password: status = %d, tokens = %+v", resp.StatusCode, fresh)
+	}
+
+	for token, want := range map[string]int{old.AccessToken: fiber.StatusUnauthorized, fresh.AccessToken: fiber.StatusOK} {
+		req := httptest.NewRequest(fiber.MethodGet, "/api/v1/users/me", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		if resp := at.send(t, req, nil, nil); resp.StatusCode != want {
+			t.Errorf("GET /users/me: status = %d, want %d", resp.StatusCode, want)
+		}
+	}
+}
@@ -6,40 +6,82 @@ import (
 	"go-fiber-template/internal/domain/dto"
 	"go-fiber-template/internal/domain/entity"
 	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
 	"go-fiber-template/lib/utils"
 	"go-fiber-template/lib/xjwt"
 	"go-fiber-template/lib/xkafka"
+	"go-fiber-template/lib/xoidc"
+	"go-fiber-template/lib/xtotp"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
 )
 
 type service struct {
-	userRepo    interfaces.UserRepository
-	kafkaClient *xkafka.Client
+	userRepo         interfaces.UserRepository
+	roleRepo         interfaces.RoleRepository
+	refreshTokenRepo interfaces.RefreshTokenRepository
+	resetTokenRepo   interfaces.PasswordResetTokenRepository
+	recoveryCodeRepo interfaces.RecoveryCodeRepository
+	loginAttemptRepo interfaces.LoginAttemptRepository
+	sessionRepo      interfaces.SessionRepository
+	revocationStore  interfaces.TokenRevocationStore
+	oauthStateRepo   interfaces.OAuthStateRepository
+	identityRepo     interfaces.ExternalIdentityRepository
+	oauthProviders   xoidc.Providers
+	totp             *xtotp.TOTP
+	kafkaClient      *xkafka.Client
+	auditService     interfaces.AuditService
 }
 
 func (s *service) Login(c *fiber.Ctx, req *dto.LoginRequest) (*dto.LoginResponse, error) {
-	byEmail, err := s.userRepo.FindByEmail(req.Email)
-	if err != nil {
-		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid email or password")
+	accountKey := accountThrottleKey(req.Email)
+	ipKey := ipThrottleKey(c.IP())
+	for _, key := range []string{accountKey, ipKey} {
+		if err := s.checkThrottle(c, key); err != nil {
+			return nil, err
+		}
 	}
 
-	if !utils.CheckPasswordHash(req.Password, byEmail.Password) {
+	byEmail, _ := s.userRepo.FindByEmail(req.Email)
+	if byEmail == nil {
+		byEmail = s.findRestorable(req.Email)
+	}
+	if byEmail == nil || !utils.CheckPasswordHash(req.Password, byEmail.Password) {
+		// Failures for unknown addresses are recorded too, they show which accounts are being guessed
+		record := &interfaces.AuditRecord{
+			Action:     constant.AuditActionLoginFailed,
+			TargetType: constant.AuditTargetUser,
+			Diff:       map[string]string{"email": req.Email},
+		}
+		if byEmail != nil {
+			record.TargetID = strconv.Itoa(int(byEmail.ID))
+		}
+		s.auditService.Record(c, record)
+
+		for _, key := range []string{accountKey, ipKey} {
+			if err := s.recordFailure(c, key, byEmail); err != nil {
+				return nil, err
+			}
+		}
 		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid email or password")
 	}
 
-	accessToken, err := xjwt.GenerateToken(byEmail, xjwt.TokenTypeAccess)
-	if err != nil {
+	// The network keeps its failures, one valid password must not make room for more guesses from it
+	if err := s.clearThrottle(accountKey); err != nil {
 		return nil, err
 	}
 
-	if err := s.sendLoginNotification(c, byEmail); err != nil {
+	if err := s.admit(byEmail); err != nil {
 		return nil, err
 	}
 
-	return &dto.LoginResponse{
-		AccessToken: accessToken,
-	}, nil
+	s.rehashPassword(byEmail, req.Password)
+
+	return s.completeLogin(c, byEmail)
 }
 
 func (s *service) Register(c *fiber.Ctx, req *dto.RegisterRequest) (*dto.RegisterResponse, error) {
@@ -58,24 +100,309 @@ func (s *service) Register(c *fiber.Ctx, req *dto.RegisterRequest) (*dto.Registe
 	}
 	user.Password = hash
 
+	defaultRole, err := s.roleRepo.FindByName(config.Config.Auth.DefaultRole)
+	if err != nil {
+		return nil, err
+	}
+	user.Roles = []entity.Role{*defaultRole}
+
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, err
 	}
+	s.auditSelf(c, constant.AuditActionRegister, user.ID, nil)
+
+	if err := s.sendVerificationEmail(c, user); err != nil {
+		return nil, err
+	}
+
+	// Unverified users cannot log in under this policy, so do not hand them tokens either
+	if config.Config.Auth.EmailVerificationPolicy == constant.EmailVerificationLogin {
+		return &dto.RegisterResponse{
+			UserID: user.ID,
+		}, nil
+	}
 
-	accessToken, err := xjwt.GenerateToken(user, xjwt.TokenTypeAccess)
+	tokens, err := s.startSession(c, user)
 	if err != nil {
 		return nil, err
 	}
 
 	return &dto.RegisterResponse{
-		UserID:      user.ID,
-		AccessToken: accessToken,
+		UserID:       user.ID,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		CSRFToken:    tokens.CSRFToken,
+	}, nil
+}
+
+func (s *service) Refresh(c *fiber.Ctx, req *dto.RefreshTokenRequest) (*dto.LoginResponse, error) {
+	refreshToken := req.RefreshToken
+	if refreshToken == "" && config.Config.Auth.CookieMode {
+		refreshToken = c.Cookies(constant.CookieRefreshToken)
+	}
+	if refreshToken == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Missing refresh token")
+	}
+
+	claims, err := xjwt.ParseToken(refreshToken, xjwt.TokenTypeRefresh)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired refresh token")
+	}
+
+	stored, err := s.refreshTokenRepo.FindByTokenID(claims.ID)
+	if err != nil || stored.RevokedAt != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired refresh token")
+	}
+
+	fresh, err := s.refreshTokenRepo.MarkUsed(stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		// A rotated refresh token is being presented again, so either the client or an
+		// attacker holds a stolen copy. Kill the whole family to force a new login.
+		log.Warn().Uint("user_id", stored.UserID).Str("family", stored.Family).Msg("Refresh token reuse detected")
+		if err := s.revokeSession(stored.Family); err != nil {
+			return nil, err
+		}
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired refresh token")
+	}
+
+	user, err := s.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired refresh token")
+	}
+	if user.DisabledAt != nil {
+		return nil, fiber.NewError(fiber.StatusForbidden, "Account has been disabled")
+	}
+
+	tokens, err := s.issueTokens(user, stored.Family)
+	if err != nil {
+		return nil, err
+	}
+
+	// The session lasts as long as the refresh token it was just given
+	if err := s.sessionRepo.Extend(stored.Family, time.Now().Add(xjwt.TTL(xjwt.TokenTypeRefresh))); err != nil {
+		return nil, err
+	}
+
+	if err := setTokenCookies(c, tokens, false); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (s *service) Logout(c *fiber.Ctx) error {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	if err := s.revocationStore.Revoke(claims.ID, claims.UserID(), claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+
+	if claims.Family != "" {
+		if err := s.revokeSession(claims.Family); err != nil {
+			return err
+		}
+	}
+
+	s.audit(c, constant.AuditActionLogout, claims.UserID(), nil)
+	clearTokenCookies(c)
+	return nil
+}
+
+func (s *service) LogoutAll(c *fiber.Ctx) error {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	if err := s.revocationStore.Revoke(claims.ID, claims.UserID(), claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+
+	if err := s.revokeAllTokens(claims.UserID()); err != nil {
+		return err
+	}
+
+	s.audit(c, constant.AuditActionLogoutAll, claims.UserID(), nil)
+	clearTokenCookies(c)
+	return nil
+}
+
+// revokeAllTokens invalidates every access and refresh token issued to the user so far.
+func (s *service) revokeAllTokens(userID uint) error {
+	if err := s.revocationStore.RevokeUser(userID, time.Now().Add(xjwt.TTL(xjwt.TokenTypeAccess))); err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.RevokeByUserID(userID); err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeByUserID(userID)
+}
+
+// rehashPassword upgrades the stored hash of the user to the current hashing policy. It can only
+// be done at login, when the password is known in clear text. Failing to do so is not fatal,
+// the old hash keeps working and the upgrade is attempted again on the next login.
+func (s *service) rehashPassword(user *entity.User, password string) {
+	if !utils.PasswordNeedsRehash(user.Password) {
+		return
+	}
+
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		log.Error().Err(err).Uint("user_id", user.ID).Msg("Failed to rehash password")
+		return
+	}
+
+	user.Password = hash
+	if err := s.userRepo.Update(user); err != nil {
+		log.Error().Err(err).Uint("user_id", user.ID).Msg("Failed to store rehashed password")
+	}
+}
+
+// admit refuses disabled users. Users who log in during the grace period of their deletion are
+// restored by startSession, once every factor has been checked.
+func (s *service) admit(user *entity.User) error {
+	if user.DisabledAt != nil {
+		return fiber.NewError(fiber.StatusForbidden, "Account has been disabled")
+	}
+
+	return nil
+}
+
+// completeLogin starts a session for an authenticated user, unless their email still has to be
+// verified or a second factor is required.
+func (s *service) completeLogin(c *fiber.Ctx, user *entity.User) (*dto.LoginResponse, error) {
+	if user.VerifiedAt == nil && config.Config.Auth.EmailVerificationPolicy == constant.EmailVerificationLogin {
+		return nil, fiber.NewError(fiber.StatusForbidden, "Email address is not verified")
+	}
+
+	// The first factor alone is not enough, the client has to complete the login at /auth/2fa/verify
+	if user.TwoFactorEnabledAt != nil {
+		mfaToken, err := xjwt.GenerateToken(user, xjwt.TokenTypeMfaPending)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LoginResponse{
+			MfaRequired: true,
+			MfaToken:    mfaToken,
+		}, nil
+	}
+
+	tokens, err := s.startSession(c, user)
+	if err != nil {
+		return nil, err
+	}
+	s.auditSelf(c, constant.AuditActionLogin, user.ID, nil)
+
+	if err := s.sendLoginNotification(c, user); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// startSession records a new login of the user from the device making the request
+// and issues the first tokens of the session.
+func (s *service) startSession(c *fiber.Ctx, user *entity.User) (*dto.LoginResponse, error) {
+	// Logging in during the grace period cancels the deletion of the account, but only once every
+	// factor has been checked, a password alone does not bring back an account protected by 2FA
+	if user.DeletedAt.Valid {
+		if err := s.restoreAccount(c, user); err != nil {
+			return nil, err
+		}
+	}
+
+	session := &entity.Session{
+		UserID:     user.ID,
+		Family:     xjwt.NewTokenID(),
+		UserAgent:  c.Get(fiber.HeaderUserAgent),
+		IPAddress:  c.IP(),
+		LastSeenAt: time.Now(),
+		ExpiresAt:  time.Now().Add(xjwt.TTL(xjwt.TokenTypeRefresh)),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.issueTokens(user, session.Family)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setTokenCookies(c, tokens, true); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// revokeSession ends a session along with every refresh token issued for it.
+func (s *service) revokeSession(family string) error {
+	if err := s.sessionRepo.RevokeByFamily(family); err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeFamily(family)
+}
+
+// issueTokens mints an access token and a refresh token belonging to the given family
+// and records the refresh token so that it can be rotated later.
+func (s *service) issueTokens(user *entity.User, family string) (*dto.LoginResponse, error) {
+	accessToken, err := xjwt.GenerateToken(user, xjwt.TokenTypeAccess, xjwt.WithFamily(family))
+	if err != nil {
+		return nil, err
+	}
+
+	tokenID := xjwt.NewTokenID()
+	refreshToken, err := xjwt.GenerateToken(user, xjwt.TokenTypeRefresh, xjwt.WithID(tokenID), xjwt.WithFamily(family))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepo.Create(&entity.RefreshToken{
+		UserID:    user.ID,
+		Family:    family,
+		TokenID:   tokenID,
+		ExpiresAt: time.Now().Add(xjwt.TTL(xjwt.TokenTypeRefresh)),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &dto.LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 	}, nil
 }
 
+// currentUser loads the user the token of the request was issued to.
+func (s *service) currentUser(c *fiber.Ctx) (*entity.User, error) {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	user, _ := s.userRepo.FindByID(claims.UserID())
+	if user == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	return user, nil
+}
+
 func (s *service) validateUnique(user *entity.User) error {
 	if user.Email != "" {
 		byEmail, _ := s.userRepo.FindByEmail(user.Email)
+		if byEmail == nil {
+			// Deleted users keep their email address until the grace period is over
+			byEmail, _ = s.userRepo.FindDeletedByEmail(user.Email)
+		}
 		if byEmail != nil && byEmail.ID != user.ID {
 			return fiber.NewError(fiber.StatusConflict, "Email already exists")
 		}
@@ -92,24 +419,75 @@ func (s *service) sendLoginNotification(c *fiber.Ctx, user *entity.User) error {
 		Body:    fmt.Sprintf("Hello %s, you have successfully logged in to your account.", user.Name),
 	}
 
+	if err := s.publishEmail(c, "auth.login", emailConfig); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to send login notification")
+	}
+
+	return nil
+}
+
+// publishEmail hands an email over to the email service through Kafka.
+func (s *service) publishEmail(c *fiber.Ctx, topic string, emailConfig *interfaces.EmailConfig) error {
 	emailConfigByte, err := json.Marshal(emailConfig)
 	if err != nil {
 		return err
 	}
 
-	if err := s.kafkaClient.Produce(c.Context(), "auth.login", emailConfigByte); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to send login notification")
-	}
+	return s.kafkaClient.Produce(c.Context(), topic, emailConfigByte)
+}
 
-	return nil
+// audit records an action on the account of the user, taken by whoever made the request.
+func (s *service) audit(c *fiber.Ctx, action string, userID uint, diff any) {
+	s.auditService.Record(c, &interfaces.AuditRecord{
+		Action:     action,
+		TargetType: constant.AuditTargetUser,
+		TargetID:   strconv.Itoa(int(userID)),
+		Diff:       diff,
+	})
+}
+
+// auditSelf records an action the user took on their own account in a request that is not
+// authenticated as them yet, such as a login.
+func (s *service) auditSelf(c *fiber.Ctx, action string, userID uint, diff any) {
+	s.auditService.Record(c, &interfaces.AuditRecord{
+		Action:     action,
+		TargetType: constant.AuditTargetUser,
+		TargetID:   strconv.Itoa(int(userID)),
+		ActorID:    &userID,
+		Diff:       diff,
+	})
 }
 
 func NewService(
 	userRepo interfaces.UserRepository,
+	roleRepo interfaces.RoleRepository,
+	refreshTokenRepo interfaces.RefreshTokenRepository,
+	resetTokenRepo interfaces.PasswordResetTokenRepository,
+	recoveryCodeRepo interfaces.RecoveryCodeRepository,
+	loginAttemptRepo interfaces.LoginAttemptRepository,
+	sessionRepo interfaces.SessionRepository,
+	revocationStore interfaces.TokenRevocationStore,
+	oauthStateRepo interfaces.OAuthStateRepository,
+	identityRepo interfaces.ExternalIdentityRepository,
+	oauthProviders xoidc.Providers,
+	totp *xtotp.TOTP,
 	kafkaClient *xkafka.Client,
+	auditService interfaces.AuditService,
 ) interfaces.AuthService {
 	return &service{
-		userRepo:    userRepo,
-		kafkaClient: kafkaClient,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		resetTokenRepo:   resetTokenRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		sessionRepo:      sessionRepo,
+		revocationStore:  revocationStore,
+		oauthStateRepo:   oauthStateRepo,
+		identityRepo:     identityRepo,
+		oauthProviders:   oauthProviders,
+		totp:             totp,
+		kafkaClient:      kafkaClient,
+		auditService:     auditService,
 	}
 }
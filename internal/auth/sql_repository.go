@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *refreshTokenRepository) Create(data *entity.RefreshToken) error {
+	return r.db.Create(data).Error
+}
+
+func (r *refreshTokenRepository) FindByTokenID(tokenID string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	if err := r.db.Where("token_id = ?", tokenID).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) MarkUsed(id uint) (bool, error) {
+	// The conditional update makes concurrent refreshes of the same token race safely:
+	// only one of them affects a row.
+	result := r.db.Model(&entity.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(family string) error {
+	return r.db.Model(&entity.RefreshToken{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *refreshTokenRepository) RevokeByUserID(userID uint) error {
+	return r.db.Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) interfaces.RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+type passwordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *passwordResetTokenRepository) Create(data *entity.PasswordResetToken) error {
+	return r.db.Create(data).Error
+}
+
+func (r *passwordResetTokenRepository) FindByTokenHash(tokenHash string) (*entity.PasswordResetToken, error) {
+	var token entity.PasswordResetToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *passwordResetTokenRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&entity.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *passwordResetTokenRepository) InvalidateByUserID(userID uint) error {
+	return r.db.Model(&entity.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) interfaces.PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: db}
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func (r *recoveryCodeRepository) ReplaceForUser(userID uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]entity.RecoveryCode, 0, len(codeHashes))
+		for _, codeHash := range codeHashes {
+			codes = append(codes, entity.RecoveryCode{UserID: userID, CodeHash: codeHash})
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *recoveryCodeRepository) Use(userID uint, codeHash string) (bool, error) {
+	result := r.db.Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *recoveryCodeRepository) DeleteByUserID(userID uint) error {
+	return r.db.Unscoped().Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) interfaces.RecoveryCodeRepository {
+	return &recoveryCodeRepository{db: db}
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func (r *loginAttemptRepository) FindByKey(key string) (*entity.LoginAttempt, error) {
+	var attempt entity.LoginAttempt
+	if err := r.db.Where("throttle_key = ?", key).First(&attempt).Error; err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+func (r *loginAttemptRepository) Increment(key string, staleBefore, now time.Time) (*entity.LoginAttempt, error) {
+	stale := "(login_attempts.locked_until IS NULL OR login_attempts.locked_until <= ?) AND login_attempts.last_failure_at < ?"
+	attempt := &entity.LoginAttempt{
+		ThrottleKey:   key,
+		Failures:      1,
+		LastFailureAt: now,
+	}
+	err := r.db.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "throttle_key"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"failures":        gorm.Expr("CASE WHEN "+stale+" THEN 1 ELSE login_attempts.failures + 1 END", now, staleBefore),
+				"locked_until":    gorm.Expr("CASE WHEN "+stale+" THEN NULL ELSE login_attempts.locked_until END", now, staleBefore),
+				"last_failure_at": now,
+			}),
+		},
+		clause.Returning{},
+	).Create(attempt).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+func (r *loginAttemptRepository) Lock(id uint, until, now time.Time) (bool, error) {
+	result := r.db.Model(&entity.LoginAttempt{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until <= ?)", id, now).
+		Updates(map[string]any{
+			"locked_until": until,
+			"failures":     0,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *loginAttemptRepository) DeleteByKey(key string) error {
+	return r.db.Where("throttle_key = ?", key).Delete(&entity.LoginAttempt{}).Error
+}
+
+func (r *loginAttemptRepository) DeleteStale(before time.Time) error {
+	return r.db.Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, time.Now()).
+		Delete(&entity.LoginAttempt{}).Error
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) interfaces.LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+type oauthStateRepository struct {
+	db *gorm.DB
+}
+
+func (r *oauthStateRepository) Create(data *entity.OAuthState) error {
+	return r.db.Create(data).Error
+}
+
+func (r *oauthStateRepository) Consume(stateHash string) (*entity.OAuthState, error) {
+	var state entity.OAuthState
+	if err := r.db.Where("state_hash = ?", stateHash).First(&state).Error; err != nil {
+		return nil, err
+	}
+
+	// Only one of concurrent callbacks with the same state deletes the row
+	result := r.db.Unscoped().Where("id = ?", state.ID).Delete(&entity.OAuthState{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &state, nil
+}
+
+func (r *oauthStateRepository) DeleteExpired(before time.Time) error {
+	return r.db.Unscoped().Where("expires_at < ?", before).Delete(&entity.OAuthState{}).Error
+}
+
+func NewOAuthStateRepository(db *gorm.DB) interfaces.OAuthStateRepository {
+	return &oauthStateRepository{db: db}
+}
+
+type externalIdentityRepository struct {
+	db *gorm.DB
+}
+
+func (r *externalIdentityRepository) Create(data *entity.ExternalIdentity) error {
+	return r.db.Create(data).Error
+}
+
+func (r *externalIdentityRepository) FindByProviderSubject(provider, subject string) (*entity.ExternalIdentity, error) {
+	var identity entity.ExternalIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+func NewExternalIdentityRepository(db *gorm.DB) interfaces.ExternalIdentityRepository {
+	return &externalIdentityRepository{db: db}
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sqlRevocationStore struct {
+	db *gorm.DB
+}
+
+func (s *sqlRevocationStore) Revoke(tokenID string, userID uint, expiresAt time.Time) error {
+	return s.db.Create(&entity.RevokedToken{
+		TokenID:   &tokenID,
+		UserID:    userID,
+		RevokedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+func (s *sqlRevocationStore) Consume(tokenID string, userID uint, expiresAt time.Time) (bool, error) {
+	// The unique jti lets only the first insert through
+	result := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_id"}},
+		DoNothing: true,
+	}).Create(&entity.RevokedToken{
+		TokenID:   &tokenID,
+		UserID:    userID,
+		RevokedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (s *sqlRevocationStore) RevokeUser(userID uint, expiresAt time.Time) error {
+	return s.db.Create(&entity.RevokedToken{
+		UserID:    userID,
+		RevokedAt: revocationCutoff(time.Now()),
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+func (s *sqlRevocationStore) IsRevoked(tokenID string, userID uint, issuedAt time.Time) (bool, error) {
+	var count int64
+	err := s.db.Model(&entity.RevokedToken{}).
+		Where("token_id = ?", tokenID).
+		Or("token_id IS NULL AND user_id = ? AND revoked_at >= ?", userID, issuedAt).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *sqlRevocationStore) PurgeExpired() error {
+	return s.db.Where("expires_at < ?", time.Now()).Delete(&entity.RevokedToken{}).Error
+}
+
+// revocationCutoff truncates the time of a user-wide revocation to whole seconds, the resolution
+// of the iat claim. Tokens issued within the second of the revocation cannot be told apart from
+// those issued before it, so they are revoked as well.
+func revocationCutoff(t time.Time) time.Time {
+	return t.Truncate(time.Second)
+}
+
+func NewSQLRevocationStore(db *gorm.DB) interfaces.TokenRevocationStore {
+	return &sqlRevocationStore{db: db}
+}
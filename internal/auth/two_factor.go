@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const recoveryCodeCount = 10
+
+func (s *service) SetupTwoFactor(c *fiber.Ctx) (*dto.TwoFactorSetupResponse, error) {
+	user, err := s.currentUser(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		return nil, fiber.NewError(fiber.StatusConflict, "Two-factor authentication is already enabled")
+	}
+
+	secret, err := s.totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	user.TwoFactorSecret = secret
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorSetupResponse{
+		Secret: secret,
+		URI:    s.totp.URI(secret, user.Email),
+	}, nil
+}
+
+func (s *service) ConfirmTwoFactor(c *fiber.Ctx, req *dto.TwoFactorCodeRequest) (*dto.TwoFactorConfirmResponse, error) {
+	user, err := s.currentUser(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		return nil, fiber.NewError(fiber.StatusConflict, "Two-factor authentication is already enabled")
+	}
+	if user.TwoFactorSecret == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Two-factor authentication has not been set up")
+	}
+
+	if err := s.confirmTwoFactorCode(c, user, req.Code); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.TwoFactorEnabledAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.generateRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit(c, constant.AuditActionTwoFactorEnabled, user.ID, nil)
+
+	return &dto.TwoFactorConfirmResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (s *service) DisableTwoFactor(c *fiber.Ctx, req *dto.TwoFactorCodeRequest) error {
+	user, err := s.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if user.TwoFactorEnabledAt == nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Two-factor authentication is not enabled")
+	}
+	if err := s.confirmTwoFactorCode(c, user, req.Code); err != nil {
+		return err
+	}
+
+	user.TwoFactorSecret = ""
+	user.TwoFactorEnabledAt = nil
+	user.TwoFactorLastStep = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	if err := s.recoveryCodeRepo.DeleteByUserID(user.ID); err != nil {
+		return err
+	}
+
+	s.audit(c, constant.AuditActionTwoFactorDisabled, user.ID, nil)
+	return nil
+}
+
+func (s *service) VerifyTwoFactor(c *fiber.Ctx, req *dto.TwoFactorVerifyRequest) (*dto.LoginResponse, error) {
+	claims, err := xjwt.ParseToken(req.MfaToken, xjwt.TokenTypeMfaPending)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired MFA token")
+	}
+
+	// This also refuses MFA tokens issued before the user logged out everywhere
+	revoked, err := s.revocationStore.IsRevoked(claims.ID, claims.UserID(), claims.IssuedAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired MFA token")
+	}
+
+	user, _ := s.userRepo.FindByID(claims.UserID())
+	if user == nil {
+		// Users deleted during the grace period are restored once they pass the second factor
+		user = s.findRestorable(claims.UserEmail)
+	}
+	if user == nil || user.ID != claims.UserID() || user.TwoFactorEnabledAt == nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired MFA token")
+	}
+
+	throttleKeys := []string{twoFactorThrottleKey(user.ID), ipThrottleKey(c.IP())}
+	for _, key := range throttleKeys {
+		if err := s.checkThrottle(c, key); err != nil {
+			return nil, err
+		}
+	}
+
+	// An MFA token allows a single attempt, so guessing codes requires the password every time
+	consumed, err := s.revocationStore.Consume(claims.ID, user.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired MFA token")
+	}
+
+	var valid bool
+	if req.Code != "" {
+		valid, err = s.checkTwoFactorCode(user, req.Code)
+	} else {
+		valid, err = s.recoveryCodeRepo.Use(user.ID, utils.HashToken(normalizeRecoveryCode(req.RecoveryCode)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		s.auditSelf(c, constant.AuditActionLoginFailed, user.ID, map[string]string{"reason": "invalid_two_factor_code"})
+		for _, key := range throttleKeys {
+			if err := s.recordFailure(c, key, user); err != nil {
+				return nil, err
+			}
+		}
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid two-factor code, please log in again")
+	}
+
+	// The network keeps its failures, one good code must not make room for more guesses from it
+	if err := s.clearThrottle(twoFactorThrottleKey(user.ID)); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.startSession(c, user)
+	if err != nil {
+		return nil, err
+	}
+	s.auditSelf(c, constant.AuditActionLogin, user.ID, nil)
+
+	if err := s.sendLoginNotification(c, user); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// checkTwoFactorCode validates a TOTP code and records its time step so that the same code cannot
+// be used twice, not even by concurrent requests.
+func (s *service) checkTwoFactorCode(user *entity.User, code string) (bool, error) {
+	valid, step := s.totp.Validate(user.TwoFactorSecret, code)
+	if !valid || step <= user.TwoFactorLastStep {
+		return false, nil
+	}
+
+	used, err := s.userRepo.UseTwoFactorStep(user.ID, step)
+	if err != nil || !used {
+		return false, err
+	}
+
+	user.TwoFactorLastStep = step
+	return true, nil
+}
+
+// confirmTwoFactorCode checks the code a logged in user gives to change their second factor. Failures
+// count against the same key as those of the login, so a stolen session cannot guess codes either.
+func (s *service) confirmTwoFactorCode(c *fiber.Ctx, user *entity.User, code string) error {
+	key := twoFactorThrottleKey(user.ID)
+	if err := s.checkThrottle(c, key); err != nil {
+		return err
+	}
+
+	valid, err := s.checkTwoFactorCode(user, code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		if err := s.recordFailure(c, key, user); err != nil {
+			return err
+		}
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid two-factor code")
+	}
+
+	return s.clearThrottle(key)
+}
+
+// generateRecoveryCodes replaces the recovery codes of the user and returns the new ones in clear text.
+func (s *service) generateRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+
+		// The codes are random, so a plain hash is enough and lets them be looked up by it
+		codes = append(codes, code[:4]+"-"+code[4:])
+		hashes = append(hashes, utils.HashToken(code))
+	}
+
+	if err := s.recoveryCodeRepo.ReplaceForUser(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -0,0 +1,58 @@
+package auth_test
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestTwoFactorCodesAreSingleUse(t *testing.T) {
+	at := newAuthTest(t)
+	at.createUser(t, "user@example.com", "Password1!")
+	token := at.passwordLogin(t, "user@example.com", "Password1!").AccessToken
+
+	var setup dto.TwoFactorSetupResponse
+	at.postAs(t, token, "/api/v1/auth/2fa/setup", nil, &setup)
+	code, err := at.totp.Code(setup.Secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var confirm dto.TwoFactorConfirmResponse
+	if resp := at.postAs(t, token, "/api/v1/auth/2fa/confirm", dto.TwoFactorCodeRequest{Code: code}, &confirm); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("confirm status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	verify := func(req dto.TwoFactorVerifyRequest) int {
+		return at.post(t, "/api/v1/auth/2fa/verify", req, nil).StatusCode
+	}
+	mfaToken := func() string {
+		data := at.passwordLogin(t, "user@example.com", "Password1!")
+		if !data.MfaRequired {
+			t.Fatal("login did not ask for the second factor")
+		}
+		return data.MfaToken
+	}
+
+	if status := verify(dto.TwoFactorVerifyRequest{MfaToken: mfaToken(), Code: code}); status != fiber.StatusUnauthorized {
+		t.Errorf("replayed code: status = %d, want %d", status, fiber.StatusUnauthorized)
+	}
+
+	used := mfaToken()
+	recovery := confirm.RecoveryCodes[0]
+	if status := verify(dto.TwoFactorVerifyRequest{MfaToken: used, RecoveryCode: recovery}); status != fiber.StatusOK {
+		t.Fatalf("recovery code: status = %d, want %d", status, fiber.StatusOK)
+	}
+	if status := verify(dto.TwoFactorVerifyRequest{MfaToken: mfaToken(), RecoveryCode: recovery}); status != fiber.StatusUnauthorized {
+		t.Errorf("replayed recovery code: status = %d, want %d", status, fiber.StatusUnauthorized)
+	}
+
+	next, err := at.totp.Code(setup.Secret, time.Now().Add(30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := verify(dto.TwoFactorVerifyRequest{MfaToken: used, Code: next}); status != fiber.StatusUnauthorized {
+		t.Errorf("replayed MFA token: status = %d, want %d", status, fiber.StatusUnauthorized)
+	}
+}
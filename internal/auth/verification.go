@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"fmt"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/xjwt"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *service) VerifyEmail(c *fiber.Ctx, token string) error {
+	claims, err := xjwt.ParseToken(token, xjwt.TokenTypeEmailVerification)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid or expired verification token")
+	}
+
+	// Verification tokens are single-use: a used token is put on the revocation list
+	revoked, err := s.revocationStore.IsRevoked(claims.ID, claims.UserID(), claims.IssuedAt.Time)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid or expired verification token")
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID())
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid or expired verification token")
+	}
+
+	switch {
+	case user.Email == claims.UserEmail:
+		if user.VerifiedAt == nil {
+			now := time.Now()
+			user.VerifiedAt = &now
+			if err := s.userRepo.Update(user); err != nil {
+				return err
+			}
+			s.auditSelf(c, constant.AuditActionEmailVerified, user.ID, nil)
+		}
+	case user.PendingEmail != "" && user.PendingEmail == claims.UserEmail:
+		now := time.Now()
+		user.VerifiedAt = &now
+		if err := s.confirmEmailChange(c, user); err != nil {
+			return err
+		}
+	default:
+		// The token was issued for an address the user no longer has or wants
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid or expired verification token")
+	}
+
+	return s.revocationStore.Revoke(claims.ID, user.ID, claims.ExpiresAt.Time)
+}
+
+func (s *service) ResendVerification(c *fiber.Ctx, req *dto.ResendVerificationRequest) error {
+	// Always succeed so that the endpoint cannot be used to find out which emails are registered
+	user, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil || user.VerifiedAt != nil {
+		return nil
+	}
+
+	if err := s.sendVerificationEmail(c, user); err != nil {
+		log.Error().Err(err).Uint("user_id", user.ID).Msg("Failed to resend verification email")
+	}
+
+	return nil
+}
+
+func (s *service) sendVerificationEmail(c *fiber.Ctx, user *entity.User) error {
+	token, err := xjwt.GenerateToken(user, xjwt.TokenTypeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/v1/auth/verify?token=%s", config.Config.BaseURL, url.QueryEscape(token))
+	emailConfig := &interfaces.EmailConfig{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Hello %s, please verify your email address by opening this link: %s", user.Name, link),
+	}
+
+	if err := s.publishEmail(c, "auth.verify_email", emailConfig); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to send verification email")
+	}
+
+	return nil
+}
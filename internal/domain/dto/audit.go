@@ -0,0 +1,32 @@
+package dto
+
+import "encoding/json"
+
+type AuditEventDto struct {
+	ID             uint            `json:"id"`
+	ActorID        *uint           `json:"actor_id"`
+	ImpersonatorID *uint           `json:"impersonator_id,omitempty"`
+	Action         string          `json:"action"`
+	TargetType     string          `json:"target_type"`
+	TargetID       string          `json:"target_id"`
+	IPAddress      string          `json:"ip_address"`
+	UserAgent      string          `json:"user_agent"`
+	RequestID      string          `json:"request_id"`
+	Diff           json.RawMessage `json:"diff,omitempty" swaggertype:"object"`
+	CreatedAt      string          `json:"created_at"`
+}
+
+type AuditFilter struct {
+	ActorID uint `json:"actor_id" query:"actor_id"`
+	// Action matches one action, or every action of a group when it ends with a dot, such as "auth.".
+	Action      string `json:"action" query:"action" validate:"omitempty,max=100"`
+	TargetType  string `json:"target_type" query:"target_type" validate:"omitempty,max=50"`
+	TargetID    string `json:"target_id" query:"target_id" validate:"omitempty,max=255"`
+	IPAddress   string `json:"ip_address" query:"ip_address" validate:"omitempty,ip"`
+	RequestID   string `json:"request_id" query:"request_id" validate:"omitempty,max=64"`
+	CreatedFrom string `json:"created_from" query:"created_from" validate:"omitempty,datetime=2006-01-02"`
+	CreatedTo   string `json:"created_to" query:"created_to" validate:"omitempty,datetime=2006-01-02"`
+	Order       string `json:"order" query:"order" validate:"omitempty,oneof=asc desc"`
+	Page        int    `json:"page" query:"page" validate:"omitempty,min=1"`
+	Limit       int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+}
@@ -3,12 +3,14 @@ package dto
 type RegisterRequest struct {
 	Name     string `json:"name" validate:"required,min=3,max=100"`
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Password string `json:"password" validate:"required,x_password"`
 }
 
 type RegisterResponse struct {
-	UserID      uint   `json:"user_id"`
-	AccessToken string `json:"access_token"`
+	UserID       uint   `json:"user_id"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	CSRFToken    string `json:"csrf_token,omitempty"`
 }
 
 type LoginRequest struct {
@@ -17,5 +19,74 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// CSRFToken is set in cookie mode and must be sent in the X-CSRF-Token header with every
+	// state-changing request authenticated by the cookies.
+	CSRFToken string `json:"csrf_token,omitempty"`
+	// MfaRequired is set instead of the tokens when the user has two-factor authentication enabled.
+	// MfaToken must then be sent to /auth/2fa/verify together with a code.
+	MfaRequired bool   `json:"mfa_required,omitempty"`
+	MfaToken    string `json:"mfa_token,omitempty"`
+}
+
+// OAuthCallbackRequest is the query string the provider redirects back with.
+// Error is set instead of Code when the user did not grant access.
+type OAuthCallbackRequest struct {
+	Code             string `json:"code" query:"code"`
+	State            string `json:"state" query:"state" validate:"required"`
+	Error            string `json:"error" query:"error"`
+	ErrorDescription string `json:"error_description" query:"error_description"`
+}
+
+type ImpersonationResponse struct {
+	// AccessToken acts as the user and cannot be refreshed.
 	AccessToken string `json:"access_token"`
+	ExpiresAt   string `json:"expires_at"`
+	UserID      uint   `json:"user_id"`
+}
+
+type RefreshTokenRequest struct {
+	// RefreshToken may be left out in cookie mode, the refresh token cookie is used then.
+	RefreshToken string `json:"refresh_token"`
+}
+
+type CSRFTokenResponse struct {
+	CSRFToken string `json:"csrf_token"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,x_password"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+type TwoFactorConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorVerifyRequest struct {
+	MfaToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,numeric,len=6"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
 }
@@ -0,0 +1,34 @@
+package dto
+
+type DataExportDto struct {
+	ID          uint    `json:"id"`
+	Format      string  `json:"format"`
+	Status      string  `json:"status"`
+	CreatedAt   string  `json:"created_at"`
+	CompletedAt *string `json:"completed_at"`
+	// ExpiresAt is when the download link sent by email stops working.
+	ExpiresAt *string `json:"expires_at"`
+}
+
+type CreateDataExportRequest struct {
+	// Format of the archive, zip when left out.
+	Format string `json:"format" validate:"omitempty,oneof=json zip"`
+}
+
+// UserDataDto is the content of a data export.
+type UserDataDto struct {
+	ExportedAt  string          `json:"exported_at"`
+	Profile     UserDto         `json:"profile"`
+	Sessions    []SessionDto    `json:"sessions"`
+	APIKeys     []APIKeyDto     `json:"api_keys"`
+	Products    []ProductDto    `json:"products"`
+	Emails      []EmailLogDto   `json:"emails"`
+	AuditEvents []AuditEventDto `json:"audit_events"`
+}
+
+type EmailLogDto struct {
+	Recipient string `json:"recipient"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+	SentAt    string `json:"sent_at"`
+}
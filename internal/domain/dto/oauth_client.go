@@ -0,0 +1,89 @@
+package dto
+
+type OAuthClientDto struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`
+	ClientID   string   `json:"client_id"`
+	Scopes     []string `json:"scopes"`
+	LastUsedAt *string  `json:"last_used_at"`
+	RevokedAt  *string  `json:"revoked_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+type CreateOAuthClientRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required,max=100"`
+}
+
+type CreateOAuthClientResponse struct {
+	OAuthClientDto
+	// ClientSecret is only returned once, when the client is registered.
+	ClientSecret string `json:"client_secret"`
+}
+
+// OAuthTokenRequest is the form sent to the token endpoint. The client may authenticate with
+// HTTP Basic authentication instead of ClientID and ClientSecret.
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	// Scope is a space-delimited list of scopes. All scopes of the client are granted when empty.
+	Scope string `form:"scope"`
+}
+
+// OAuthTokenResponse and OAuthErrorResponse follow RFC 6749 rather than ResponseDto,
+// so that standard OAuth client libraries understand them.
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OAuthIntrospectRequest is the form sent to the introspection endpoint (RFC 7662, section 2.1).
+type OAuthIntrospectRequest struct {
+	Token string `form:"token"`
+	// TokenTypeHint is accepted for compatibility but not needed, the type is part of our tokens.
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// OAuthIntrospectResponse describes a token as in RFC 7662, section 2.2. Only Active is set for
+// tokens that are not active, so that nothing is disclosed about them.
+type OAuthIntrospectResponse struct {
+	Active bool   `json:"active"`
+	Scope  string `json:"scope,omitempty"`
+	// ClientID is set on tokens issued to OAuth clients.
+	ClientID string `json:"client_id,omitempty"`
+	// Username is the email address of the user the token was issued to.
+	Username string `json:"username,omitempty"`
+	// TokenType is the type claim of the token, such as access, refresh or client.
+	TokenType string           `json:"token_type,omitempty"`
+	ExpiresAt int64            `json:"exp,omitempty"`
+	IssuedAt  int64            `json:"iat,omitempty"`
+	Subject   string           `json:"sub,omitempty"`
+	Issuer    string           `json:"iss,omitempty"`
+	TokenID   string           `json:"jti,omitempty"`
+	Roles     []string         `json:"roles,omitempty"`
+	Actor     *OAuthTokenActor `json:"act,omitempty"`
+}
+
+// OAuthTokenActor is the administrator acting as the subject of an impersonation token.
+type OAuthTokenActor struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+}
+
+// OAuthUserInfoResponse holds the standard OpenID Connect claims about the user.
+type OAuthUserInfoResponse struct {
+	Subject       string `json:"sub"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
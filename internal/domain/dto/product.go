@@ -2,6 +2,7 @@ package dto
 
 type ProductDto struct {
 	ID          uint    `json:"id"`
+	OwnerID     *uint   `json:"owner_id"`
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
 	Price       float64 `json:"price"`
@@ -10,6 +11,22 @@ type ProductDto struct {
 	UpdatedAt   string  `json:"updated_at"`
 }
 
+type ProductFilter struct {
+	// Name matches part of the name, ignoring case.
+	Name     string   `json:"name" query:"name" validate:"omitempty,max=100"`
+	MinPrice *float64 `json:"min_price" query:"min_price" validate:"omitempty,min=0"`
+	MaxPrice *float64 `json:"max_price" query:"max_price" validate:"omitempty,min=0"`
+	InStock  *bool    `json:"in_stock" query:"in_stock"`
+	// Sort is a comma-separated list of fields, each sorted in descending order when prefixed
+	// with "-", such as "price,-created_at".
+	Sort string `json:"sort" query:"sort" validate:"omitempty,max=100,x_sort=id name price stock created_at updated_at,excluded_with=Cursor"`
+	Page int    `json:"page" query:"page" validate:"omitempty,min=1,excluded_with=Cursor"`
+	// Cursor switches to cursor pagination, newest first. An empty cursor reads the first page and
+	// the next ones are read with the cursors returned along with it.
+	Cursor *string `json:"cursor" query:"cursor" validate:"omitempty,max=512"`
+	Limit  int     `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
 type CreateProductRequest struct {
 	Name        string  `json:"name" validate:"required"`
 	Description string  `json:"description" validate:"required"`
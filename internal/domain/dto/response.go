@@ -4,6 +4,16 @@ type ResponseDto struct {
 	Message string               `json:"message"`
 	Errors  []ErrorValidationDto `json:"errors"`
 	Data    any                  `json:"data"`
+	// NextCursor and PrevCursor are set on listings paginated with a cursor. They are left out
+	// when there is no page on that side.
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// CursorPageDto holds the cursors to the pages around a page of a listing.
+type CursorPageDto struct {
+	NextCursor string
+	PrevCursor string
 }
 
 type ErrorValidationDto struct {
@@ -0,0 +1,15 @@
+package dto
+
+type RoleDto struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+type SetRolesRequest struct {
+	Roles []string `json:"roles" validate:"dive,required"`
+}
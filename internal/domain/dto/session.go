@@ -0,0 +1,12 @@
+package dto
+
+type SessionDto struct {
+	ID         uint    `json:"id"`
+	UserAgent  string  `json:"user_agent"`
+	IPAddress  string  `json:"ip_address"`
+	CreatedAt  string  `json:"created_at"`
+	LastSeenAt string  `json:"last_seen_at"`
+	RevokedAt  *string `json:"revoked_at,omitempty"`
+	// Current tells whether the session is the one the request was made with.
+	Current bool `json:"current"`
+}
@@ -1,9 +1,58 @@
 package dto
 
 type UserDto struct {
-	ID        uint   `json:"id"`
-	Name      string `json:"name"`
-	Email     string `json:"email"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID            uint     `json:"id"`
+	Name          string   `json:"name"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	PendingEmail  string   `json:"pending_email,omitempty"`
+	Roles         []string `json:"roles"`
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+type UpdateProfileRequest struct {
+	Name string `json:"name" validate:"required,min=3,max=100"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,x_password"`
+}
+
+type ChangeEmailRequest struct {
+	Email           string `json:"email" validate:"required,email"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+type DeleteAccountResponse struct {
+	// PurgeAt is when the account stops being restorable and its personal data is anonymized.
+	PurgeAt string `json:"purge_at"`
+}
+
+// AdminUserDto is a user as seen by the administrators.
+type AdminUserDto struct {
+	UserDto
+	TwoFactorEnabled bool    `json:"two_factor_enabled"`
+	DisabledAt       *string `json:"disabled_at"`
+	DeletedAt        *string `json:"deleted_at"`
+}
+
+type UserFilter struct {
+	// Search matches part of the name or the email address.
+	Search   string `json:"search" query:"search" validate:"omitempty,max=100"`
+	Verified *bool  `json:"verified" query:"verified"`
+	Disabled *bool  `json:"disabled" query:"disabled"`
+	// Deleted decides whether deleted users are left out, included or the only ones listed.
+	Deleted     string `json:"deleted" query:"deleted" validate:"omitempty,oneof=exclude include only"`
+	CreatedFrom string `json:"created_from" query:"created_from" validate:"omitempty,datetime=2006-01-02"`
+	CreatedTo   string `json:"created_to" query:"created_to" validate:"omitempty,datetime=2006-01-02"`
+	Sort        string `json:"sort" query:"sort" validate:"omitempty,oneof=id name email created_at"`
+	Order       string `json:"order" query:"order" validate:"omitempty,oneof=asc desc"`
+	Page        int    `json:"page" query:"page" validate:"omitempty,min=1"`
+	Limit       int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
 }
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// AuditEvent is an entry of the security audit log. Entries are deleted once they are older than
+// the retention period, or hold the email address of a user being purged. The only change ever
+// made is blanking the IP address and user agent of the entries tied to a user being purged.
+type AuditEvent struct {
+	ID uint `gorm:"primarykey"`
+	// ActorID is the user who acted, nil for anonymous requests such as failed logins.
+	ActorID *uint `gorm:"index"`
+	// ImpersonatorID is the administrator who acted as ActorID, if any.
+	ImpersonatorID *uint
+	Action         string `gorm:"not null;index"`
+	TargetType     string `gorm:"not null;default:'';index:idx_audit_events_target"`
+	TargetID       string `gorm:"not null;default:'';index:idx_audit_events_target"`
+	IPAddress      string `gorm:"not null;default:''"`
+	UserAgent      string `gorm:"not null;default:''"`
+	RequestID      string `gorm:"not null;default:'';index"`
+	// Diff is a JSON object holding the changes made or the details of the action. The migrations
+	// store it as JSONB and enforce the append-only rule with a PL/pgSQL trigger, both of which
+	// need PostgreSQL. On other databases the column is plain text and nothing stops updates.
+	Diff      *string
+	CreatedAt time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DataExport is an archive of everything stored about a user, assembled in the background.
+type DataExport struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;index"`
+	Format string `gorm:"not null"`
+	Status string `gorm:"not null;index"`
+	// FilePath and TokenHash are set once the archive is ready, the token is the one of the download link.
+	FilePath  string `gorm:"not null;default:''"`
+	TokenHash string `gorm:"not null;default:'';index"`
+	// ClaimedAt is when an instance started assembling the archive. Exports that stay claimed for too
+	// long belong to an instance that crashed, and are failed by the next run of the job.
+	ClaimedAt   *time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "gorm.io/gorm"
+
+// EmailLog records an email sent by the application.
+type EmailLog struct {
+	gorm.Model
+	Recipient string `gorm:"not null;index"`
+	Subject   string `gorm:"not null"`
+	// Body is stored without its links, which may carry tokens.
+	Body string `gorm:"not null"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "gorm.io/gorm"
+
+// ExternalIdentity links a user to their account at an OAuth or OpenID Connect provider.
+type ExternalIdentity struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	Provider string `gorm:"not null;uniqueIndex:idx_external_identities_provider_subject"`
+	// Subject is the stable identifier of the user at the provider.
+	Subject string `gorm:"not null;uniqueIndex:idx_external_identities_provider_subject"`
+	// Email is the address the provider reported when the identity was linked.
+	Email string `gorm:"not null;default:''"`
+}
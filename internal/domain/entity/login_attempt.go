@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// LoginAttempt counts the recent failed logins of a throttling key, such as an account or an IP address.
+type LoginAttempt struct {
+	ID            uint      `gorm:"primarykey"`
+	ThrottleKey   string    `gorm:"not null;unique"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null"`
+	LockedUntil   *time.Time
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OAuthState is an authorization request sent to a provider whose callback has not arrived yet.
+type OAuthState struct {
+	gorm.Model
+	StateHash    string    `gorm:"not null;unique"`
+	Provider     string    `gorm:"not null"`
+	Nonce        string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"not null"`
+}
+
+// TableName keeps GORM from naming the table o_auth_states.
+func (OAuthState) TableName() string {
+	return "oauth_states"
+}
@@ -4,6 +4,8 @@ import "gorm.io/gorm"
 
 type Product struct {
 	gorm.Model
+	// OwnerID is the user who created the product. Products created before owners were recorded have none.
+	OwnerID     *uint   `gorm:"index"`
 	Name        string  `gorm:"not null"`
 	Description string  `gorm:"not null"`
 	Price       float64 `gorm:"not null"`
@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCode struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	CodeHash string `gorm:"not null"`
+	UsedAt   *time.Time
+}
@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// RevokedToken is an entry of the token revocation list. Entries without a TokenID
+// revoke every token of the user issued up to RevokedAt.
+type RevokedToken struct {
+	ID        uint      `gorm:"primarykey"`
+	TokenID   *string   `gorm:"unique"`
+	UserID    uint      `gorm:"not null;index"`
+	RevokedAt time.Time `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "gorm.io/gorm"
+
+type Role struct {
+	gorm.Model
+	Name        string       `gorm:"not null;unique"`
+	Permissions []Permission `gorm:"many2many:role_permissions"`
+}
+
+type Permission struct {
+	gorm.Model
+	Name string `gorm:"not null;unique"`
+}
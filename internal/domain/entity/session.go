@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Session is a login of a user on a device. Every token issued for it shares its family.
+type Session struct {
+	gorm.Model
+	UserID     uint      `gorm:"not null;index"`
+	Family     string    `gorm:"not null;unique"`
+	UserAgent  string    `gorm:"not null;default:''"`
+	IPAddress  string    `gorm:"not null;default:''"`
+	LastSeenAt time.Time `gorm:"not null"`
+	// ExpiresAt is when the last refresh token issued for the session expires. Refreshing extends it.
+	ExpiresAt time.Time `gorm:"not null;index"`
+	RevokedAt *time.Time
+}
@@ -1,10 +1,40 @@
 package entity
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	gorm.Model
-	Name     string `gorm:"not null"`
-	Email    string `gorm:"not null;unique"`
-	Password string `gorm:"not null"`
+	Name       string `gorm:"not null"`
+	Email      string `gorm:"not null;unique"`
+	Password   string `gorm:"not null"`
+	VerifiedAt *time.Time
+	Roles      []Role `gorm:"many2many:user_roles"`
+
+	// PendingEmail is the address the user asked to change to. It replaces Email once verified.
+	PendingEmail string `gorm:"not null;default:''"`
+
+	// TwoFactorSecret is set on setup and only enforced once TwoFactorEnabledAt is set by a confirmation.
+	TwoFactorSecret    string `gorm:"not null;default:''"`
+	TwoFactorEnabledAt *time.Time
+	// TwoFactorLastStep is the time step of the last accepted code, so that a code cannot be replayed.
+	TwoFactorLastStep int64 `gorm:"not null;default:0"`
+
+	// DisabledAt is set while an administrator keeps the user from logging in.
+	DisabledAt *time.Time
+
+	// PurgedAt is set once the personal data of a deleted user has been anonymized.
+	PurgedAt *time.Time
+}
+
+// RoleNames returns the names of the roles loaded with the user.
+func (u *User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, role := range u.Roles {
+		names = append(names, role.Name)
+	}
+	return names
 }
@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/lib/xjwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyRepository interface {
+	Create(data *entity.APIKey) error
+	FindByKeyHash(keyHash string) (*entity.APIKey, error)
+	FindActiveByUserID(userID uint) ([]entity.APIKey, error)
+	// FindByUserID also finds the revoked and expired keys of the user.
+	FindByUserID(userID uint) ([]entity.APIKey, error)
+	Revoke(id uint, userID uint) (bool, error)
+	Touch(id uint) error
+}
+
+type APIKeyService interface {
+	Create(c *fiber.Ctx, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error)
+	FindAll(c *fiber.Ctx) ([]dto.APIKeyDto, error)
+	Revoke(c *fiber.Ctx, id uint) error
+	// Authenticate resolves an API key into the same claims an access token of its owner would carry.
+	Authenticate(c *fiber.Ctx, key string) (*xjwt.TokenClaims, error)
+}
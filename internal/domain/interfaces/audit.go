@@ -0,0 +1,41 @@
+package interfaces
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditRepository interface {
+	Create(data *entity.AuditEvent) error
+	// Search finds a page of the events matching the filter and counts all of them.
+	Search(filter *dto.AuditFilter) ([]entity.AuditEvent, int64, error)
+	// FindByUserID finds the events of the actions the user took or that were taken on their account.
+	FindByUserID(userID uint) ([]entity.AuditEvent, error)
+	// DeleteBefore deletes the events recorded before the given time.
+	DeleteBefore(before time.Time) error
+}
+
+// AuditRecord describes an action for AuditService.Record. Who acted, and from where, is read from
+// the request.
+type AuditRecord struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	// ActorID is the user acting in requests not authenticated as them, such as logins.
+	ActorID *uint
+	// Diff holds the changes made or the details of the action and is stored as JSON.
+	Diff any
+}
+
+type AuditService interface {
+	// Record appends an event to the audit log. Failures are logged rather than returned, so that
+	// the audited action is not failed after the fact.
+	Record(c *fiber.Ctx, record *AuditRecord)
+	// Search lists the events matching the filter and sets the pagination headers.
+	Search(c *fiber.Ctx, filter *dto.AuditFilter) ([]dto.AuditEventDto, error)
+	// PurgeExpired deletes the events older than the retention period.
+	PurgeExpired() error
+}
@@ -2,11 +2,129 @@ package interfaces
 
 import (
 	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+type RefreshTokenRepository interface {
+	Create(data *entity.RefreshToken) error
+	FindByTokenID(tokenID string) (*entity.RefreshToken, error)
+	// MarkUsed flags the token as used and reports false if it had already been used or revoked.
+	MarkUsed(id uint) (bool, error)
+	RevokeFamily(family string) error
+	RevokeByUserID(userID uint) error
+}
+
+type PasswordResetTokenRepository interface {
+	Create(data *entity.PasswordResetToken) error
+	FindByTokenHash(tokenHash string) (*entity.PasswordResetToken, error)
+	// MarkUsed flags the token as used and reports false if it had already been used.
+	MarkUsed(id uint) (bool, error)
+	// InvalidateByUserID marks every outstanding token of the user as used.
+	InvalidateByUserID(userID uint) error
+}
+
+type RecoveryCodeRepository interface {
+	// ReplaceForUser deletes the existing codes of the user and stores the given hashes instead.
+	ReplaceForUser(userID uint, codeHashes []string) error
+	// Use flags the unused code of the user with the given hash as used and reports false if there
+	// is no such code.
+	Use(userID uint, codeHash string) (bool, error)
+	DeleteByUserID(userID uint) error
+}
+
+type LoginAttemptRepository interface {
+	FindByKey(key string) (*entity.LoginAttempt, error)
+	// Increment atomically counts a failure against the key at the given time and returns the
+	// updated attempt. The count starts over when the key is not locked and its last failure
+	// happened before staleBefore.
+	Increment(key string, staleBefore, now time.Time) (*entity.LoginAttempt, error)
+	// Lock locks the attempt until the given time and resets its failures, unless it is locked
+	// already. It reports whether the attempt was locked by this call.
+	Lock(id uint, until, now time.Time) (bool, error)
+	DeleteByKey(key string) error
+	// DeleteStale deletes the attempts whose last failure happened before the given time.
+	DeleteStale(before time.Time) error
+}
+
+type OAuthStateRepository interface {
+	Create(data *entity.OAuthState) error
+	// Consume deletes the state and returns it, so that every state is accepted only once.
+	Consume(stateHash string) (*entity.OAuthState, error)
+	// DeleteExpired deletes the states of authorization requests that expired before the given time.
+	DeleteExpired(before time.Time) error
+}
+
+type ExternalIdentityRepository interface {
+	Create(data *entity.ExternalIdentity) error
+	FindByProviderSubject(provider, subject string) (*entity.ExternalIdentity, error)
+}
+
+// LockoutEvent is published on the auth.lockout topic when an account gets locked.
+type LockoutEvent struct {
+	UserID      uint      `json:"user_id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	IP          string    `json:"ip"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// TokenRevocationStore keeps track of tokens that must be rejected before they expire.
+type TokenRevocationStore interface {
+	// Revoke revokes a single token by its jti until it expires.
+	Revoke(tokenID string, userID uint, expiresAt time.Time) error
+	// Consume revokes a single-use token by its jti until it expires. It reports false when the
+	// token had been revoked already, so that only one of concurrent uses gets through.
+	Consume(tokenID string, userID uint, expiresAt time.Time) (bool, error)
+	// RevokeUser revokes every token of the user issued before now. The entry is kept until expiresAt,
+	// after which all of those tokens have expired on their own.
+	RevokeUser(userID uint, expiresAt time.Time) error
+	IsRevoked(tokenID string, userID uint, issuedAt time.Time) (bool, error)
+	// PurgeExpired deletes entries whose tokens have expired anyway.
+	PurgeExpired() error
+}
+
 type AuthService interface {
 	Register(c *fiber.Ctx, req *dto.RegisterRequest) (*dto.RegisterResponse, error)
 	Login(c *fiber.Ctx, req *dto.LoginRequest) (*dto.LoginResponse, error)
+	// RequestMagicLink emails a single-use link for logging in without a password.
+	RequestMagicLink(c *fiber.Ctx, req *dto.MagicLinkRequest) error
+	MagicLinkLogin(c *fiber.Ctx, token string) (*dto.LoginResponse, error)
+	// OAuthLogin returns the URL of the provider the user is redirected to for logging in.
+	OAuthLogin(c *fiber.Ctx, provider string) (string, error)
+	// OAuthCallback completes a login with the provider, linking the identity to the user with the same verified email.
+	OAuthCallback(c *fiber.Ctx, provider string, req *dto.OAuthCallbackRequest) (*dto.LoginResponse, error)
+	Refresh(c *fiber.Ctx, req *dto.RefreshTokenRequest) (*dto.LoginResponse, error)
+	Logout(c *fiber.Ctx) error
+	LogoutAll(c *fiber.Ctx) error
+	// CSRFToken returns the CSRF token of the session in cookie mode, for frontends on another site
+	// that cannot read its cookie.
+	CSRFToken(c *fiber.Ctx) (*dto.CSRFTokenResponse, error)
+	VerifyEmail(c *fiber.Ctx, token string) error
+	ResendVerification(c *fiber.Ctx, req *dto.ResendVerificationRequest) error
+	ForgotPassword(c *fiber.Ctx, req *dto.ForgotPasswordRequest) error
+	ResetPassword(c *fiber.Ctx, req *dto.ResetPasswordRequest) error
+	SetupTwoFactor(c *fiber.Ctx) (*dto.TwoFactorSetupResponse, error)
+	ConfirmTwoFactor(c *fiber.Ctx, req *dto.TwoFactorCodeRequest) (*dto.TwoFactorConfirmResponse, error)
+	DisableTwoFactor(c *fiber.Ctx, req *dto.TwoFactorCodeRequest) error
+	VerifyTwoFactor(c *fiber.Ctx, req *dto.TwoFactorVerifyRequest) (*dto.LoginResponse, error)
+	// ChangePassword replaces the password of the current user and logs every other device out.
+	ChangePassword(c *fiber.Ctx, req *dto.ChangePasswordRequest) (*dto.LoginResponse, error)
+	// ChangeEmail sends a verification link to the new address, which replaces the current one once verified.
+	ChangeEmail(c *fiber.Ctx, req *dto.ChangeEmailRequest) error
+	// DeleteAccount deletes the current user. The account can be restored by logging in during the grace period.
+	DeleteAccount(c *fiber.Ctx, req *dto.DeleteAccountRequest) (*dto.DeleteAccountResponse, error)
+	// Unlock lifts a lockout of the account of the user before it expires.
+	Unlock(c *fiber.Ctx, userID uint) error
+	// Disable keeps the user from logging in or using their API keys, and logs them out everywhere.
+	Disable(c *fiber.Ctx, userID uint) error
+	Enable(c *fiber.Ctx, userID uint) error
+	// ForceLogout revokes every token and session of the user.
+	ForceLogout(c *fiber.Ctx, userID uint) error
+	// Impersonate issues a short-lived access token acting as the user on behalf of the current administrator.
+	Impersonate(c *fiber.Ctx, userID uint) (*dto.ImpersonationResponse, error)
+	// EndImpersonation revokes the impersonation token of the request.
+	EndImpersonation(c *fiber.Ctx) error
 }
@@ -0,0 +1,40 @@
+package interfaces
+
+import (
+	"context"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DataExportRepository interface {
+	Create(data *entity.DataExport) error
+	FindByID(id uint, userID uint) (*entity.DataExport, error)
+	FindByTokenHash(tokenHash string) (*entity.DataExport, error)
+	FindByUserID(userID uint) ([]entity.DataExport, error)
+	FindPending() ([]entity.DataExport, error)
+	// FindInProgressByUserID finds the export of the user that is pending or being assembled, if any.
+	FindInProgressByUserID(userID uint) (*entity.DataExport, error)
+	// FindExpired finds the ready exports whose download links expired before the given time.
+	FindExpired(before time.Time) ([]entity.DataExport, error)
+	// FindStale finds the exports that are still being assembled by an instance that claimed them before the given time.
+	FindStale(claimedBefore time.Time) ([]entity.DataExport, error)
+	// Claim marks a pending export as being assembled. It reports false when another instance claimed it first.
+	Claim(id uint, claimedAt time.Time) (bool, error)
+	Update(data *entity.DataExport) error
+}
+
+type DataExportService interface {
+	// Request schedules an export of the personal data of the current user.
+	Request(c *fiber.Ctx, req *dto.CreateDataExportRequest) (*dto.DataExportDto, error)
+	FindByID(c *fiber.Ctx, id uint) (*dto.DataExportDto, error)
+	// Download sends the archive the token of a download link gives access to.
+	Download(c *fiber.Ctx, token string) error
+	// ProcessPending assembles the archives of the pending exports and emails their download links.
+	// Exports claimed longer than the claim timeout ago are failed first.
+	ProcessPending(ctx context.Context) error
+	// PurgeExpired deletes the archives whose download links expired.
+	PurgeExpired() error
+}
@@ -1,6 +1,9 @@
 package interfaces
 
-import "context"
+import (
+	"context"
+	"go-fiber-template/internal/domain/entity"
+)
 
 type EmailConfig struct {
 	To      string
@@ -8,6 +11,11 @@ type EmailConfig struct {
 	Body    string
 }
 
+type EmailLogRepository interface {
+	Create(data *entity.EmailLog) error
+	FindByRecipients(recipients []string) ([]entity.EmailLog, error)
+}
+
 type EmailService interface {
 	Send(config *EmailConfig) error
 	StartEmailConsumer(ctx context.Context, topics []string) error
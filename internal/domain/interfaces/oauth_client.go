@@ -0,0 +1,32 @@
+package interfaces
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OAuthClientRepository interface {
+	Create(data *entity.OAuthClient) error
+	FindByClientID(clientID string) (*entity.OAuthClient, error)
+	FindAll() ([]entity.OAuthClient, error)
+	// Revoke flags the client as revoked and reports false if it does not exist or was already revoked.
+	Revoke(id uint) (bool, error)
+	Touch(id uint) error
+}
+
+type OAuthClientService interface {
+	// Create registers a client. Its secret is only returned here.
+	Create(c *fiber.Ctx, req *dto.CreateOAuthClientRequest) (*dto.CreateOAuthClientResponse, error)
+	FindAll(c *fiber.Ctx) ([]dto.OAuthClientDto, error)
+	// Revoke keeps the client from getting new tokens and makes the ones it has useless.
+	Revoke(c *fiber.Ctx, id uint) error
+	// Token issues an access token with the client credentials grant.
+	Token(c *fiber.Ctx, req *dto.OAuthTokenRequest) (*dto.OAuthTokenResponse, error)
+	// Introspect tells whether a token we issued is active and describes it (RFC 7662). Only clients
+	// granted the introspection scope may use it.
+	Introspect(c *fiber.Ctx, req *dto.OAuthIntrospectRequest) (*dto.OAuthIntrospectResponse, error)
+	// Validate rejects tokens of clients that have been revoked since.
+	Validate(c *fiber.Ctx, clientID string) error
+}
@@ -3,6 +3,7 @@ package interfaces
 import (
 	"go-fiber-template/internal/domain/dto"
 	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/lib/database"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -10,7 +11,11 @@ import (
 type ProductRepository interface {
 	Create(data *entity.Product) error
 	FindByID(id uint) (*entity.Product, error)
-	FindAll() ([]entity.Product, error)
+	// FindAll finds a page of the products matching the filter and counts all of them.
+	FindAll(filter *dto.ProductFilter) ([]entity.Product, int64, error)
+	// FindAllByCursor finds the page of the products matching the filter that follows its cursor.
+	FindAllByCursor(filter *dto.ProductFilter) ([]entity.Product, *database.CursorPage, error)
+	FindByOwnerID(ownerID uint) ([]entity.Product, error)
 	Update(data *entity.Product) error
 	Delete(id uint) error
 }
@@ -18,7 +23,9 @@ type ProductRepository interface {
 type ProductService interface {
 	Create(c *fiber.Ctx, req *dto.CreateProductRequest) (*dto.ProductDto, error)
 	FindByID(c *fiber.Ctx, id uint) (*dto.ProductDto, error)
-	FindAll(c *fiber.Ctx) ([]dto.ProductDto, error)
+	// FindAll lists the products matching the filter and sets the pagination headers. The cursors
+	// are only returned when the filter asks for cursor pagination.
+	FindAll(c *fiber.Ctx, filter *dto.ProductFilter) ([]dto.ProductDto, *dto.CursorPageDto, error)
 	Update(c *fiber.Ctx, id uint, req *dto.UpdateProductRequest) (*dto.ProductDto, error)
 	Delete(c *fiber.Ctx, id uint) error
 }
@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleRepository interface {
+	FindAll() ([]entity.Role, error)
+	FindByName(name string) (*entity.Role, error)
+	FindPermissionNames(roleNames []string) ([]string, error)
+	AssignToUser(userID uint, role *entity.Role) error
+	RemoveFromUser(userID uint, role *entity.Role) error
+	ReplaceForUser(userID uint, roles []entity.Role) error
+}
+
+type RoleService interface {
+	FindAll(c *fiber.Ctx) ([]dto.RoleDto, error)
+	// AssignToUser, RemoveFromUser and SetUserRoles revoke the access tokens of the user, which
+	// carry the roles they were issued with.
+	AssignToUser(c *fiber.Ctx, userID uint, req *dto.AssignRoleRequest) error
+	RemoveFromUser(c *fiber.Ctx, userID uint, roleName string) error
+	// SetUserRoles replaces every role of a user with the given ones.
+	SetUserRoles(c *fiber.Ctx, userID uint, req *dto.SetRolesRequest) error
+	HasPermission(c *fiber.Ctx, roleNames []string, permission string) (bool, error)
+}
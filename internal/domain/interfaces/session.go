@@ -0,0 +1,34 @@
+package interfaces
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionRepository interface {
+	Create(data *entity.Session) error
+	FindByFamily(family string) (*entity.Session, error)
+	FindActiveByUserID(userID uint) ([]entity.Session, error)
+	// FindByUserID also finds the revoked sessions of the user.
+	FindByUserID(userID uint) ([]entity.Session, error)
+	FindByID(id uint, userID uint) (*entity.Session, error)
+	RevokeByFamily(family string) error
+	RevokeByUserID(userID uint) error
+	Touch(id uint, at time.Time) error
+	// Extend moves the expiry of the session to that of its latest refresh token.
+	Extend(family string, expiresAt time.Time) error
+	// DeleteExpired deletes the sessions that expired before the given time, revoked or not.
+	DeleteExpired(before time.Time) error
+}
+
+type SessionService interface {
+	FindAll(c *fiber.Ctx) ([]dto.SessionDto, error)
+	Revoke(c *fiber.Ctx, id uint) error
+	// Validate rejects tokens of revoked or expired sessions and records the activity of the session.
+	Validate(c *fiber.Ctx, family string) error
+	// PurgeExpired deletes the sessions whose refresh tokens have all expired.
+	PurgeExpired() error
+}
@@ -1,8 +1,10 @@
 package interfaces
 
 import (
+	"context"
 	"go-fiber-template/internal/domain/dto"
 	"go-fiber-template/internal/domain/entity"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,8 +15,36 @@ type UserRepository interface {
 	FindByEmail(email string) (*entity.User, error)
 	Update(user *entity.User) error
 	Delete(id uint) error
+	// Search finds a page of the users matching the filter and counts all of them.
+	Search(filter *dto.UserFilter) ([]entity.User, int64, error)
+	// FindDeletedByEmail finds a deleted user whose personal data has not been purged yet.
+	FindDeletedByEmail(email string) (*entity.User, error)
+	Restore(id uint) error
+	// UseTwoFactorStep records the time step of an accepted two-factor code, unless the same or a
+	// later step has been recorded already. It reports whether the step was recorded.
+	UseTwoFactorStep(id uint, step int64) (bool, error)
+	// FindPurgeable finds the deleted users not purged yet that were deleted before the given time.
+	FindPurgeable(before time.Time) ([]entity.User, error)
+	// Purge anonymizes the personal data of a deleted user and deletes their credentials, data exports,
+	// login throttles and the audit events holding their email address.
+	Purge(user *entity.User) error
+}
+
+// UserDeletedEvent is published on the user.deleted topic right before the personal data of a deleted
+// user is purged. It can be delivered more than once for the same user.
+type UserDeletedEvent struct {
+	UserID    uint      `json:"user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgedAt  time.Time `json:"purged_at"`
 }
 
 type UserService interface {
 	FindByID(c *fiber.Ctx, id uint) (*dto.UserDto, error)
+	// FindMe returns the profile of the user the request was authenticated as.
+	FindMe(c *fiber.Ctx) (*dto.UserDto, error)
+	UpdateMe(c *fiber.Ctx, req *dto.UpdateProfileRequest) (*dto.UserDto, error)
+	// Search lists the users matching the filter and sets the pagination headers.
+	Search(c *fiber.Ctx, filter *dto.UserFilter) ([]dto.AdminUserDto, error)
+	// PurgeDeleted purges the users whose deletion grace period is over.
+	PurgeDeleted(ctx context.Context) error
 }
@@ -2,6 +2,7 @@ package email
 
 import (
 	"encoding/json"
+	"fmt"
 	"go-fiber-template/internal/domain/interfaces"
 
 	"github.com/IBM/sarama"
@@ -21,8 +22,17 @@ func NewEmailConsumerHandler(emailService interfaces.EmailService) *emailConsume
 // HandleMessage processes the email message from Kafka
 func (h *emailConsumerHandler) HandleMessage(msg *sarama.ConsumerMessage) error {
 	var emailConfig interfaces.EmailConfig
-	if err := json.Unmarshal(msg.Value, &emailConfig); err != nil {
-		return err
+	switch msg.Topic {
+	case "auth.lockout":
+		var event interfaces.LockoutEvent
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			return err
+		}
+		emailConfig = lockoutEmail(&event)
+	default:
+		if err := json.Unmarshal(msg.Value, &emailConfig); err != nil {
+			return err
+		}
 	}
 
 	if err := h.emailService.Send(&emailConfig); err != nil {
@@ -31,3 +41,15 @@ func (h *emailConsumerHandler) HandleMessage(msg *sarama.ConsumerMessage) error
 
 	return nil
 }
+
+// lockoutEmail builds the notification sent to the owner of a locked account
+func lockoutEmail(event *interfaces.LockoutEvent) interfaces.EmailConfig {
+	return interfaces.EmailConfig{
+		To:      event.Email,
+		Subject: "Account Locked",
+		Body: fmt.Sprintf(
+			"Hello %s, your account has been locked until %s after too many failed login attempts from %s. If this was not you, consider changing your password.",
+			event.Name, event.LockedUntil.Format("2006-01-02 15:04:05"), event.IP,
+		),
+	}
+}
@@ -3,12 +3,15 @@ package email
 import (
 	"context"
 	"fmt"
+	"go-fiber-template/internal/domain/entity"
 	"go-fiber-template/internal/domain/interfaces"
 	"go-fiber-template/lib/xkafka"
+	"regexp"
 )
 
 type service struct {
-	kafkaClient *xkafka.Client
+	emailLogRepo interfaces.EmailLogRepository
+	kafkaClient  *xkafka.Client
 }
 
 func (s *service) Send(config *interfaces.EmailConfig) error {
@@ -17,7 +20,21 @@ func (s *service) Send(config *interfaces.EmailConfig) error {
 	fmt.Printf("Subject: %s\n", config.Subject)
 	fmt.Printf("Body: %s\n", config.Body)
 
-	return nil
+	// Keep a copy, users are entitled to know what was sent to them. Links are left out, they carry
+	// the tokens of password resets, login links and the like, which must not outlive the email.
+	return s.emailLogRepo.Create(&entity.EmailLog{
+		Recipient: config.To,
+		Subject:   config.Subject,
+		Body:      redactLinks(config.Body),
+	})
+}
+
+// linkPattern matches the links in the body of an email.
+var linkPattern = regexp.MustCompile(`https?://\S*[^\s.,;:!?)]`)
+
+// redactLinks replaces the links in the body of an email with a placeholder.
+func redactLinks(body string) string {
+	return linkPattern.ReplaceAllString(body, "[link removed]")
 }
 
 // StartEmailConsumer starts consuming email messages from Kafka topics
@@ -26,8 +43,9 @@ func (s *service) StartEmailConsumer(ctx context.Context, topics []string) error
 	return s.kafkaClient.Consume(ctx, topics, handler)
 }
 
-func NewService(kafkaClient *xkafka.Client) interfaces.EmailService {
+func NewService(emailLogRepo interfaces.EmailLogRepository, kafkaClient *xkafka.Client) interfaces.EmailService {
 	return &service{
-		kafkaClient: kafkaClient,
+		emailLogRepo: emailLogRepo,
+		kafkaClient:  kafkaClient,
 	}
 }
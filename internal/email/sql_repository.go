@@ -0,0 +1,31 @@
+package email
+
+import (
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+
+	"gorm.io/gorm"
+)
+
+type logRepository struct {
+	db *gorm.DB
+}
+
+func (r *logRepository) Create(data *entity.EmailLog) error {
+	return r.db.Create(data).Error
+}
+
+func (r *logRepository) FindByRecipients(recipients []string) ([]entity.EmailLog, error) {
+	var logs []entity.EmailLog
+	err := r.db.Where("recipient IN ?", recipients).
+		Order("created_at").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func NewLogRepository(db *gorm.DB) interfaces.EmailLogRepository {
+	return &logRepository{db: db}
+}
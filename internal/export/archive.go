@@ -0,0 +1,175 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// collect gathers everything stored about the user.
+func (s *service) collect(user *entity.User) (*dto.UserDataDto, error) {
+	sessions, err := s.sessionRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := s.apiKeyRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.productRepo.FindByOwnerID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := []string{user.Email}
+	if user.PendingEmail != "" {
+		recipients = append(recipients, user.PendingEmail)
+	}
+	emailLogs, err := s.emailLogRepo.FindByRecipients(recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	auditEvents, err := s.auditRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &dto.UserDataDto{
+		ExportedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Profile: dto.UserDto{
+			ID:            user.ID,
+			Name:          user.Name,
+			Email:         user.Email,
+			EmailVerified: user.VerifiedAt != nil,
+			PendingEmail:  user.PendingEmail,
+			Roles:         user.RoleNames(),
+			CreatedAt:     user.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:     user.UpdatedAt.Format("2006-01-02 15:04:05"),
+		},
+		Sessions:    make([]dto.SessionDto, 0, len(sessions)),
+		APIKeys:     make([]dto.APIKeyDto, 0, len(apiKeys)),
+		Products:    make([]dto.ProductDto, 0, len(products)),
+		Emails:      make([]dto.EmailLogDto, 0, len(emailLogs)),
+		AuditEvents: make([]dto.AuditEventDto, 0, len(auditEvents)),
+	}
+
+	for _, session := range sessions {
+		data.Sessions = append(data.Sessions, dto.SessionDto{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt.Format("2006-01-02 15:04:05"),
+			LastSeenAt: session.LastSeenAt.Format("2006-01-02 15:04:05"),
+			RevokedAt:  formatTime(session.RevokedAt),
+		})
+	}
+
+	for _, apiKey := range apiKeys {
+		data.APIKeys = append(data.APIKeys, dto.APIKeyDto{
+			ID:         apiKey.ID,
+			Name:       apiKey.Name,
+			Prefix:     apiKey.Prefix,
+			Scopes:     apiKey.ScopeList(),
+			ExpiresAt:  formatTime(apiKey.ExpiresAt),
+			LastUsedAt: formatTime(apiKey.LastUsedAt),
+			RevokedAt:  formatTime(apiKey.RevokedAt),
+			CreatedAt:  apiKey.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	for _, product := range products {
+		data.Products = append(data.Products, dto.ProductDto{
+			ID:          product.ID,
+			OwnerID:     product.OwnerID,
+			Name:        product.Name,
+			Description: product.Description,
+			Price:       product.Price,
+			Stock:       product.Stock,
+			CreatedAt:   product.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:   product.UpdatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	for _, emailLog := range emailLogs {
+		data.Emails = append(data.Emails, dto.EmailLogDto{
+			Recipient: emailLog.Recipient,
+			Subject:   emailLog.Subject,
+			Body:      emailLog.Body,
+			SentAt:    emailLog.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	for _, event := range auditEvents {
+		eventDto := dto.AuditEventDto{
+			ID:             event.ID,
+			ActorID:        event.ActorID,
+			ImpersonatorID: event.ImpersonatorID,
+			Action:         event.Action,
+			TargetType:     event.TargetType,
+			TargetID:       event.TargetID,
+			IPAddress:      event.IPAddress,
+			UserAgent:      event.UserAgent,
+			RequestID:      event.RequestID,
+			CreatedAt:      event.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if event.Diff != nil {
+			eventDto.Diff = json.RawMessage(*event.Diff)
+		}
+		data.AuditEvents = append(data.AuditEvents, eventDto)
+	}
+
+	return data, nil
+}
+
+// writeArchive stores the data in the export directory, either as plain JSON or as a
+// ZIP archive holding data.json, and returns the path of the file.
+func writeArchive(export *entity.DataExport, data *dto.UserDataDto) (string, error) {
+	if err := os.MkdirAll(config.Config.Export.Dir, 0o700); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(config.Config.Export.Dir, fmt.Sprintf("%d.%s", export.ID, export.Format))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if export.Format == constant.DataExportFormatJSON {
+		if err := encode(file, data); err != nil {
+			return path, err
+		}
+		return path, file.Close()
+	}
+
+	archive := zip.NewWriter(file)
+	entry, err := archive.Create("data.json")
+	if err != nil {
+		return path, err
+	}
+	if err := encode(entry, data); err != nil {
+		return path, err
+	}
+	if err := archive.Close(); err != nil {
+		return path, err
+	}
+
+	return path, file.Close()
+}
+
+func encode(w io.Writer, data *dto.UserDataDto) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
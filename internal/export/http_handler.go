@@ -0,0 +1,99 @@
+package export
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/middleware"
+	"go-fiber-template/lib/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type httpHandler struct {
+	dataExportService interfaces.DataExportService
+}
+
+// NewHttpHandler registers the export routes on the API root, because the download
+// link sent by email is opened without the credentials the other routes need.
+func NewHttpHandler(r fiber.Router, dataExportService interfaces.DataExportService) {
+	handler := &httpHandler{
+		dataExportService: dataExportService,
+	}
+
+	protected := middleware.Protected()
+	r.Post("/users/me/export", protected, middleware.DenyImpersonation(), middleware.RequireVerifiedEmail(), middleware.Validate[dto.CreateDataExportRequest](), handler.Request)
+	r.Get("/users/me/export/:id", protected, handler.FindByID)
+	r.Get("/exports/:token", handler.Download)
+}
+
+// @Summary		Export personal data
+// @Description	Request an export of everything stored about the current user. The archive is assembled in the background and a download link is sent by email once it is ready.
+// @Tags			User
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			request	body		dto.CreateDataExportRequest	true	"Create data export request"
+// @Success		202		{object}	dto.ResponseDto{data=dto.DataExportDto}
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		403		{object}	dto.ResponseDto
+// @Failure		409		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/users/me/export [post]
+func (h *httpHandler) Request(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.CreateDataExportRequest](c)
+	data, err := h.dataExportService.Request(c, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(dto.ResponseDto{
+		Message: "Data export requested, a download link will be sent by email once it is ready",
+		Data:    data,
+	})
+}
+
+// @Summary		Get data export
+// @Description	Get the status of a data export of the current user
+// @Tags			User
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id	path		int	true	"Data export ID"
+// @Success		200	{object}	dto.ResponseDto{data=dto.DataExportDto}
+// @Failure		400	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/users/me/export/{id} [get]
+func (h *httpHandler) FindByID(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid data export ID")
+	}
+
+	data, err := h.dataExportService.FindByID(c, uint(id))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Data export found",
+		Data:    data,
+	})
+}
+
+// @Summary		Download data export
+// @Description	Download the archive of a data export with the link sent by email
+// @Tags			User
+// @Produce		application/zip
+// @Produce		application/json
+// @Param			token	path	string	true	"Download token"
+// @Success		200
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/exports/{token} [get]
+func (h *httpHandler) Download(c *fiber.Ctx) error {
+	return h.dataExportService.Download(c, c.Params("token"))
+}
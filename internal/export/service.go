@@ -0,0 +1,270 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"go-fiber-template/lib/xkafka"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type service struct {
+	dataExportRepo interfaces.DataExportRepository
+	userRepo       interfaces.UserRepository
+	sessionRepo    interfaces.SessionRepository
+	apiKeyRepo     interfaces.APIKeyRepository
+	productRepo    interfaces.ProductRepository
+	emailLogRepo   interfaces.EmailLogRepository
+	auditRepo      interfaces.AuditRepository
+	kafkaClient    *xkafka.Client
+}
+
+func (s *service) Request(c *fiber.Ctx, req *dto.CreateDataExportRequest) (*dto.DataExportDto, error) {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	_, err := s.dataExportRepo.FindInProgressByUserID(claims.UserID())
+	if err == nil {
+		return nil, fiber.NewError(fiber.StatusConflict, "A data export is already in progress")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	format := req.Format
+	if format == "" {
+		format = constant.DataExportFormatZIP
+	}
+
+	export := &entity.DataExport{
+		UserID: claims.UserID(),
+		Format: format,
+		Status: constant.DataExportPending,
+	}
+	if err := s.dataExportRepo.Create(export); err != nil {
+		return nil, err
+	}
+
+	return constructDataExportDto(export), nil
+}
+
+func (s *service) FindByID(c *fiber.Ctx, id uint) (*dto.DataExportDto, error) {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	export, err := s.dataExportRepo.FindByID(id, claims.UserID())
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Data export not found")
+	}
+
+	return constructDataExportDto(export), nil
+}
+
+func (s *service) Download(c *fiber.Ctx, token string) error {
+	export, err := s.dataExportRepo.FindByTokenHash(utils.HashToken(token))
+	if err != nil || export.Status != constant.DataExportReady || export.ExpiresAt.Before(time.Now()) {
+		return fiber.NewError(fiber.StatusNotFound, "Invalid or expired download link")
+	}
+
+	// Deleting the account takes the exports of its data down with it
+	if _, err := s.userRepo.FindByID(export.UserID); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Invalid or expired download link")
+	}
+
+	filename := fmt.Sprintf("personal-data-%s.%s", export.CompletedAt.Format("2006-01-02"), export.Format)
+	return c.Download(export.FilePath, filename)
+}
+
+func (s *service) ProcessPending(ctx context.Context) error {
+	// The instance assembling these crashed or hung, failing them lets the users request a new export
+	claimTimeout := time.Duration(config.Config.Export.ClaimTimeout) * time.Second
+	stale, err := s.dataExportRepo.FindStale(time.Now().Add(-claimTimeout))
+	if err != nil {
+		return err
+	}
+	for _, export := range stale {
+		log.Warn().Uint("data_export_id", export.ID).Msg("Giving up on data export that was not assembled in time")
+		if err := s.fail(&export); err != nil {
+			return err
+		}
+	}
+
+	exports, err := s.dataExportRepo.FindPending()
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		claimedAt := time.Now()
+		claimed, err := s.dataExportRepo.Claim(export.ID, claimedAt)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		export.Status = constant.DataExportProcessing
+		export.ClaimedAt = &claimedAt
+
+		if err := s.process(ctx, &export); err != nil {
+			log.Error().Err(err).Uint("data_export_id", export.ID).Msg("Failed to assemble data export")
+			if err := s.fail(&export); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *service) PurgeExpired() error {
+	exports, err := s.dataExportRepo.FindExpired(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if err := os.Remove(export.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		export.Status = constant.DataExportExpired
+		export.FilePath = ""
+		export.TokenHash = ""
+		if err := s.dataExportRepo.Update(&export); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// process writes the archive of the export and emails its download link to the user.
+func (s *service) process(ctx context.Context, export *entity.DataExport) error {
+	user, err := s.userRepo.FindByID(export.UserID)
+	if err != nil {
+		return err
+	}
+
+	data, err := s.collect(user)
+	if err != nil {
+		return err
+	}
+
+	path, err := writeArchive(export, data)
+	export.FilePath = path
+	if err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(config.Config.Export.ExpiredAt) * time.Second)
+	export.Status = constant.DataExportReady
+	export.TokenHash = utils.HashToken(token)
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+
+	if err := s.dataExportRepo.Update(export); err != nil {
+		return err
+	}
+
+	// Without the email the archive cannot be downloaded, so failing to send it fails the export
+	return s.publishReady(ctx, user, token, expiresAt)
+}
+
+// fail marks the export as failed and deletes whatever was written for it.
+func (s *service) fail(export *entity.DataExport) error {
+	if export.FilePath != "" {
+		if err := os.Remove(export.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Error().Err(err).Uint("data_export_id", export.ID).Msg("Failed to delete data export archive")
+		}
+	}
+
+	export.Status = constant.DataExportFailed
+	export.FilePath = ""
+	export.TokenHash = ""
+	export.CompletedAt = nil
+	export.ExpiresAt = nil
+
+	return s.dataExportRepo.Update(export)
+}
+
+func (s *service) publishReady(ctx context.Context, user *entity.User, token string, expiresAt time.Time) error {
+	link := fmt.Sprintf("%s/api/v1/exports/%s", config.Config.BaseURL, token)
+	emailConfigByte, err := json.Marshal(&interfaces.EmailConfig{
+		To:      user.Email,
+		Subject: "Your personal data export is ready",
+		Body: fmt.Sprintf(
+			"Hello %s, the export of your personal data is ready. You can download it until %s from this link: %s",
+			user.Name, expiresAt.Format("2006-01-02 15:04:05"), link,
+		),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.kafkaClient.Produce(ctx, "user.export_ready", emailConfigByte)
+}
+
+func constructDataExportDto(export *entity.DataExport) *dto.DataExportDto {
+	return &dto.DataExportDto{
+		ID:          export.ID,
+		Format:      export.Format,
+		Status:      export.Status,
+		CreatedAt:   export.CreatedAt.Format("2006-01-02 15:04:05"),
+		CompletedAt: formatTime(export.CompletedAt),
+		ExpiresAt:   formatTime(export.ExpiresAt),
+	}
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format("2006-01-02 15:04:05")
+	return &formatted
+}
+
+func NewService(
+	dataExportRepo interfaces.DataExportRepository,
+	userRepo interfaces.UserRepository,
+	sessionRepo interfaces.SessionRepository,
+	apiKeyRepo interfaces.APIKeyRepository,
+	productRepo interfaces.ProductRepository,
+	emailLogRepo interfaces.EmailLogRepository,
+	auditRepo interfaces.AuditRepository,
+	kafkaClient *xkafka.Client,
+) interfaces.DataExportService {
+	return &service{
+		dataExportRepo: dataExportRepo,
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		apiKeyRepo:     apiKeyRepo,
+		productRepo:    productRepo,
+		emailLogRepo:   emailLogRepo,
+		auditRepo:      auditRepo,
+		kafkaClient:    kafkaClient,
+	}
+}
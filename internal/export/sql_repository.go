@@ -0,0 +1,101 @@
+package export
+
+import (
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/constant"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func (r *repository) Create(data *entity.DataExport) error {
+	return r.db.Create(data).Error
+}
+
+func (r *repository) FindByID(id uint, userID uint) (*entity.DataExport, error) {
+	var export entity.DataExport
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *repository) FindByTokenHash(tokenHash string) (*entity.DataExport, error) {
+	var export entity.DataExport
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *repository) FindByUserID(userID uint) ([]entity.DataExport, error) {
+	var exports []entity.DataExport
+	if err := r.db.Where("user_id = ?", userID).Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *repository) FindPending() ([]entity.DataExport, error) {
+	var exports []entity.DataExport
+	if err := r.db.Where("status = ?", constant.DataExportPending).Order("created_at").Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *repository) FindInProgressByUserID(userID uint) (*entity.DataExport, error) {
+	var export entity.DataExport
+	err := r.db.Where("user_id = ? AND status IN ?", userID, []string{constant.DataExportPending, constant.DataExportProcessing}).
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *repository) FindExpired(before time.Time) ([]entity.DataExport, error) {
+	var exports []entity.DataExport
+	if err := r.db.Where("status = ? AND expires_at < ?", constant.DataExportReady, before).Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *repository) FindStale(claimedBefore time.Time) ([]entity.DataExport, error) {
+	var exports []entity.DataExport
+	err := r.db.Where("status = ? AND claimed_at < ?", constant.DataExportProcessing, claimedBefore).
+		Find(&exports).Error
+	if err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *repository) Claim(id uint, claimedAt time.Time) (bool, error) {
+	// Only one of the instances running the job affects the row
+	result := r.db.Model(&entity.DataExport{}).
+		Where("id = ? AND status = ?", id, constant.DataExportPending).
+		Updates(map[string]any{
+			"status":     constant.DataExportProcessing,
+			"claimed_at": claimedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) Update(data *entity.DataExport) error {
+	return r.db.Save(data).Error
+}
+
+func NewRepository(db *gorm.DB) interfaces.DataExportRepository {
+	return &repository{db: db}
+}
@@ -1,17 +1,30 @@
 package infrastructure
 
 import (
+	"fmt"
+	"go-fiber-template/internal/apikey"
+	"go-fiber-template/internal/audit"
 	"go-fiber-template/internal/auth"
 	"go-fiber-template/internal/domain/interfaces"
 	"go-fiber-template/internal/email"
+	"go-fiber-template/internal/export"
+	"go-fiber-template/internal/oauth"
 	"go-fiber-template/internal/product"
+	"go-fiber-template/internal/role"
+	"go-fiber-template/internal/session"
 	"go-fiber-template/internal/user"
 	"go-fiber-template/lib/config"
 	"go-fiber-template/lib/database"
+	"go-fiber-template/lib/middleware"
+	"go-fiber-template/lib/xjwt"
 	"go-fiber-template/lib/xkafka"
 	"go-fiber-template/lib/xlogger"
+	"go-fiber-template/lib/xoidc"
+	"go-fiber-template/lib/xpassword"
+	"go-fiber-template/lib/xtotp"
 	"go-fiber-template/lib/xvalidator"
 
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 )
 
@@ -21,30 +34,151 @@ var (
 	db          *gorm.DB
 	kafkaClient *xkafka.Client
 
-	authService    interfaces.AuthService
-	userService    interfaces.UserService
-	emailService   interfaces.EmailService
-	productService interfaces.ProductService
+	revocationStore        interfaces.TokenRevocationStore
+	loginAttemptRepository interfaces.LoginAttemptRepository
+	oauthStateRepository   interfaces.OAuthStateRepository
+
+	authService        interfaces.AuthService
+	userService        interfaces.UserService
+	emailService       interfaces.EmailService
+	productService     interfaces.ProductService
+	roleService        interfaces.RoleService
+	apiKeyService      interfaces.APIKeyService
+	sessionService     interfaces.SessionService
+	exportService      interfaces.DataExportService
+	oauthClientService interfaces.OAuthClientService
+	auditService       interfaces.AuditService
 )
 
 func init() {
 	cfg = config.Setup()
 	xlogger.Setup(cfg)
 	xvalidator.Setup()
+	xjwt.Setup(cfg.Jwt)
+	xpassword.Setup(xpassword.Config{
+		Algorithm:         cfg.Password.HashAlgorithm,
+		BcryptCost:        cfg.Password.BcryptCost,
+		Argon2Memory:      cfg.Password.Argon2Memory,
+		Argon2Iterations:  cfg.Password.Argon2Iterations,
+		Argon2Parallelism: cfg.Password.Argon2Parallelism,
+	})
+	passwordMaxBytes := 0
+	if cfg.Password.HashAlgorithm == xpassword.AlgorithmBcrypt {
+		passwordMaxBytes = xpassword.BcryptMaxBytes
+	}
+	xpassword.SetupPolicy(xpassword.PolicyConfig{
+		MinLength:        cfg.Password.MinLength,
+		MaxLength:        cfg.Password.MaxLength,
+		MaxBytes:         passwordMaxBytes,
+		MinClasses:       cfg.Password.MinClasses,
+		MinEntropy:       cfg.Password.MinEntropy,
+		BreachedListFile: cfg.Password.BreachedListFile,
+	})
 
+	if cfg.Database.CursorSecret == "secret" || cfg.Database.CursorSecret == cfg.Jwt.SecretKey {
+		if cfg.GoEnv == "production" {
+			panic("DB_CURSOR_SECRET must be changed from its default value and differ from JWT_SECRET_KEY in production")
+		}
+		log.Warn().Msg("DB_CURSOR_SECRET uses its default value or JWT_SECRET_KEY, cursors can be forged")
+	}
 	dbInstance = database.New(database.Config{
-		Driver: cfg.Database.Driver,
-		Dsn:    cfg.Database.Dsn,
+		Driver:       cfg.Database.Driver,
+		Dsn:          cfg.Database.Dsn,
+		CursorSecret: cfg.Database.CursorSecret,
 	})
 	db = dbInstance.GetDB()
 
 	kafkaClient = xkafka.Setup(cfg.Kafka)
 
 	userRepository := user.NewRepository(db)
-	productRepository := product.NewRepository(db)
+	productRepository := product.NewRepository(db, dbInstance.CursorSecret())
+	roleRepository := role.NewRepository(db)
+	apiKeyRepository := apikey.NewRepository(db)
+	refreshTokenRepository := auth.NewRefreshTokenRepository(db)
+	passwordResetTokenRepository := auth.NewPasswordResetTokenRepository(db)
+	recoveryCodeRepository := auth.NewRecoveryCodeRepository(db)
+	loginAttemptRepository = auth.NewLoginAttemptRepository(db)
+	sessionRepository := session.NewRepository(db)
+	emailLogRepository := email.NewLogRepository(db)
+	dataExportRepository := export.NewRepository(db)
+	oauthClientRepository := oauth.NewRepository(db)
+	oauthStateRepository = auth.NewOAuthStateRepository(db)
+	externalIdentityRepository := auth.NewExternalIdentityRepository(db)
+	revocationStore = auth.NewSQLRevocationStore(db)
+	auditRepository := audit.NewRepository(db)
+
+	oauthProviders, err := xoidc.NewProviders(oauthProviderConfigs(cfg)...)
+	if err != nil {
+		panic(err)
+	}
+
+	auditService = audit.NewService(auditRepository)
+	authService = auth.NewService(
+		userRepository,
+		roleRepository,
+		refreshTokenRepository,
+		passwordResetTokenRepository,
+		recoveryCodeRepository,
+		loginAttemptRepository,
+		sessionRepository,
+		revocationStore,
+		oauthStateRepository,
+		externalIdentityRepository,
+		oauthProviders,
+		xtotp.New(xtotp.Config{Issuer: cfg.AppName}),
+		kafkaClient,
+		auditService,
+	)
+	userService = user.NewService(userRepository, dataExportRepository, kafkaClient)
+	emailService = email.NewService(emailLogRepository, kafkaClient)
+	productService = product.NewService(productRepository, auditService)
+	roleService = role.NewService(roleRepository, userRepository, revocationStore, auditService)
+	apiKeyService = apikey.NewService(apiKeyRepository, userRepository, roleRepository, auditService)
+	sessionService = session.NewService(sessionRepository, refreshTokenRepository, auditService)
+	exportService = export.NewService(
+		dataExportRepository,
+		userRepository,
+		sessionRepository,
+		apiKeyRepository,
+		productRepository,
+		emailLogRepository,
+		auditRepository,
+		kafkaClient,
+	)
+	oauthClientService = oauth.NewService(
+		oauthClientRepository,
+		refreshTokenRepository,
+		sessionRepository,
+		revocationStore,
+		auditService,
+	)
+
+	middleware.Setup(middleware.Config{
+		RevocationStore:    revocationStore,
+		RoleService:        roleService,
+		APIKeyService:      apiKeyService,
+		SessionService:     sessionService,
+		OAuthClientService: oauthClientService,
+	})
+}
 
-	authService = auth.NewService(userRepository, kafkaClient)
-	userService = user.NewService(userRepository)
-	emailService = email.NewService(kafkaClient)
-	productService = product.NewService(productRepository)
+// oauthProviderConfigs maps the configured OAuth providers to the configuration of xoidc.
+func oauthProviderConfigs(cfg config.AppConfig) []xoidc.Config {
+	configs := make([]xoidc.Config, 0, len(cfg.OAuth.Providers))
+	for _, provider := range cfg.OAuth.Providers {
+		configs = append(configs, xoidc.Config{
+			Name:         provider.Name,
+			Type:         provider.Type,
+			IssuerURL:    provider.IssuerURL,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  fmt.Sprintf("%s/api/v1/auth/oauth/%s/callback", cfg.BaseURL, provider.Name),
+			Scopes:       provider.Scopes,
+			AuthURL:      provider.AuthURL,
+			TokenURL:     provider.TokenURL,
+			UserInfoURL:  provider.UserInfoURL,
+			JWKSURL:      provider.JWKSURL,
+		})
+	}
+	return configs
 }
@@ -0,0 +1,49 @@
+package infrastructure
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// startJobs runs the periodic maintenance jobs until the context is cancelled.
+func startJobs(ctx context.Context) {
+	go runEvery(ctx, time.Hour, "purge_revoked_tokens", revocationStore.PurgeExpired)
+	go runEvery(ctx, time.Hour, "purge_login_attempts", purgeLoginAttempts)
+	go runEvery(ctx, time.Hour, "purge_oauth_states", func() error {
+		return oauthStateRepository.DeleteExpired(time.Now())
+	})
+	go runEvery(ctx, time.Hour, "purge_deleted_users", func() error {
+		return userService.PurgeDeleted(ctx)
+	})
+	go runEvery(ctx, time.Minute, "process_data_exports", func() error {
+		return exportService.ProcessPending(ctx)
+	})
+	go runEvery(ctx, time.Hour, "purge_data_exports", exportService.PurgeExpired)
+	go runEvery(ctx, time.Hour, "purge_audit_events", auditService.PurgeExpired)
+	go runEvery(ctx, time.Hour, "purge_sessions", sessionService.PurgeExpired)
+}
+
+// runEvery calls job every interval until the context is cancelled.
+func runEvery(ctx context.Context, interval time.Duration, name string, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job(); err != nil {
+				log.Error().Err(err).Str("job", name).Msg("Scheduled job failed")
+			}
+		}
+	}
+}
+
+// purgeLoginAttempts deletes the failed login counters that no longer throttle anything.
+func purgeLoginAttempts() error {
+	window := time.Duration(cfg.Auth.LockoutDuration) * time.Second
+	return loginAttemptRepository.DeleteStale(time.Now().Add(-window))
+}
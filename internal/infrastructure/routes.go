@@ -1,22 +1,36 @@
 package infrastructure
 
 import (
+	"go-fiber-template/internal/admin"
+	"go-fiber-template/internal/apikey"
 	x_app "go-fiber-template/internal/app"
 	"go-fiber-template/internal/auth"
 	"go-fiber-template/internal/docs"
+	"go-fiber-template/internal/export"
+	"go-fiber-template/internal/oauth"
 	"go-fiber-template/internal/product"
+	"go-fiber-template/internal/session"
 	"go-fiber-template/internal/user"
+	"go-fiber-template/internal/wellknown"
 	"go-fiber-template/lib/common"
+	"go-fiber-template/lib/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func registerRoutes(app *fiber.App) {
-	api := app.Group("/api/v1")
+	wellknown.NewHttpHandler(app.Group("/.well-known"))
+
+	api := app.Group("/api/v1", middleware.CSRF())
 	x_app.NewHttpHandler(api)
 	docs.NewHttpHandler(api.Group("/docs"))
 	auth.NewHttpHandler(api.Group("/auth"), authService)
-	user.NewHttpHandler(api.Group("/users"), userService)
+	oauth.NewHttpHandler(api.Group("/oauth"), oauthClientService, userService)
+	session.NewHttpHandler(api.Group("/auth/sessions"), sessionService)
+	user.NewHttpHandler(api.Group("/users"), userService, authService)
+	export.NewHttpHandler(api, exportService)
+	apikey.NewHttpHandler(api.Group("/api-keys"), apiKeyService)
 	product.NewHttpHandler(api.Group("/products"), productService)
+	admin.NewHttpHandler(api.Group("/admin"), userService, roleService, authService, oauthClientService, auditService)
 	app.Use(common.NotFoundHandler)
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cache"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/rs/zerolog/log"
 )
 
@@ -27,6 +28,7 @@ func Run() {
 	server = fiber.New(config.FiberCfg(cfg))
 
 	// Middleware
+	server.Use(requestid.New())
 	server.Use(fiberi18n.New(config.I18nConfig))
 	server.Use(apitally.Middleware(server, config.ApitallyCfg(cfg)))
 	server.Use(fiberzerolog.New(config.FiberZerologCfg(cfg)))
@@ -41,12 +43,14 @@ func Run() {
 	defer cancel()
 
 	go func() {
-		emailTopics := []string{"auth.login"}
+		emailTopics := []string{"auth.login", "auth.verify_email", "auth.password_reset", "auth.lockout", "auth.email_changed", "auth.account_restored", "auth.magic_link", "user.export_ready"}
 		if err := emailService.StartEmailConsumer(ctx, emailTopics); err != nil {
 			log.Error().Err(err).Msg("Failed to start email consumer")
 		}
 	}()
 
+	startJobs(ctx)
+
 	go func() {
 		log.Info().Msgf("Server is running on port %s", cfg.Port)
 		if err := server.Listen(fmt.Sprintf(":%s", cfg.Port)); err != nil {
@@ -0,0 +1,147 @@
+package oauth
+
+import (
+	"errors"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/middleware"
+	"go-fiber-template/lib/xjwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type httpHandler struct {
+	oauthClientService interfaces.OAuthClientService
+	userService        interfaces.UserService
+}
+
+func NewHttpHandler(r fiber.Router, oauthClientService interfaces.OAuthClientService, userService interfaces.UserService) {
+	handler := &httpHandler{
+		oauthClientService: oauthClientService,
+		userService:        userService,
+	}
+
+	r.Post("/token", handler.Token)
+	r.Post("/introspect", handler.Introspect)
+	r.Get("/userinfo", middleware.Protected(), handler.UserInfo)
+}
+
+// @Summary		Issue a client access token
+// @Description	Issue an access token to an OAuth client with the client_credentials grant (RFC 6749, section 4.4). The client authenticates with HTTP Basic authentication or with client_id and client_secret in the form. Responses follow RFC 6749 instead of the usual envelope.
+// @Tags			OAuth
+// @Accept			x-www-form-urlencoded
+// @Produce		application/json
+// @Security		BasicAuth
+// @Param			grant_type		formData	string	true	"Must be client_credentials"
+// @Param			scope			formData	string	false	"Space-delimited scopes, all scopes of the client when left out"
+// @Param			client_id		formData	string	false	"Client ID, unless sent with HTTP Basic authentication"
+// @Param			client_secret	formData	string	false	"Client secret, unless sent with HTTP Basic authentication"
+// @Success		200				{object}	dto.OAuthTokenResponse
+// @Failure		400				{object}	dto.OAuthErrorResponse
+// @Failure		401				{object}	dto.OAuthErrorResponse
+// @Failure		500				{object}	dto.ResponseDto
+// @Router			/oauth/token [post]
+func (h *httpHandler) Token(c *fiber.Ctx) error {
+	// Token responses must never be cached (RFC 6749, section 5.1)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	var req dto.OAuthTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.OAuthErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: "Malformed request body",
+		})
+	}
+
+	data, err := h.oauthClientService.Token(c, &req)
+	if err != nil {
+		return writeTokenError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(data)
+}
+
+// @Summary		Introspect a token
+// @Description	Tell whether a token issued by this server is active and describe it (RFC 7662). Only access, refresh and client tokens can be active, other tokens and those that are malformed, expired or revoked are reported with active set to false only. The client authenticates like at the token endpoint and must have the oauth:introspect scope.
+// @Tags			OAuth
+// @Accept			x-www-form-urlencoded
+// @Produce		application/json
+// @Security		BasicAuth
+// @Param			token			formData	string	true	"The token to introspect"
+// @Param			token_type_hint	formData	string	false	"Ignored, the type is read from the token"
+// @Param			client_id		formData	string	false	"Client ID, unless sent with HTTP Basic authentication"
+// @Param			client_secret	formData	string	false	"Client secret, unless sent with HTTP Basic authentication"
+// @Success		200				{object}	dto.OAuthIntrospectResponse
+// @Failure		400				{object}	dto.OAuthErrorResponse
+// @Failure		401				{object}	dto.OAuthErrorResponse
+// @Failure		403				{object}	dto.OAuthErrorResponse
+// @Failure		500				{object}	dto.ResponseDto
+// @Router			/oauth/introspect [post]
+func (h *httpHandler) Introspect(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	var req dto.OAuthIntrospectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.OAuthErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: "Malformed request body",
+		})
+	}
+
+	data, err := h.oauthClientService.Introspect(c, &req)
+	if err != nil {
+		return writeTokenError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(data)
+}
+
+// @Summary		Get the claims of the current user
+// @Description	Get the standard OpenID Connect claims of the user the access token was issued to. The response is not wrapped in the usual envelope.
+// @Tags			OAuth
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Success		200	{object}	dto.OAuthUserInfoResponse
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/oauth/userinfo [get]
+func (h *httpHandler) UserInfo(c *fiber.Ctx) error {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	user, err := h.userService.FindByID(c, claims.UserID())
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(dto.OAuthUserInfoResponse{
+		Subject:       claims.Subject,
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+	})
+}
+
+// writeTokenError responds with the error as described by RFC 6749, section 5.2. Other errors are
+// left to the error handler.
+func writeTokenError(c *fiber.Ctx, err error) error {
+	var tokenErr *tokenError
+	if !errors.As(err, &tokenErr) {
+		return err
+	}
+	if tokenErr.status == fiber.StatusUnauthorized {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+
+	return c.Status(tokenErr.status).JSON(dto.OAuthErrorResponse{
+		Error:            tokenErr.code,
+		ErrorDescription: tokenErr.description,
+	})
+}
@@ -0,0 +1,236 @@
+package oauth_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"go-fiber-template/internal/audit"
+	"go-fiber-template/internal/auth"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/oauth"
+	"go-fiber-template/internal/session"
+	"go-fiber-template/lib/common"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type oauthTest struct {
+	app *fiber.App
+	db  *gorm.DB
+}
+
+func newOAuthTest(t *testing.T) *oauthTest {
+	t.Helper()
+
+	config.Config.Jwt = config.JwtConfig{Algorithm: "HS256", SecretKey: "test", ExpiredAt: 60, RefreshExpiredAt: 600}
+	xjwt.Setup(config.Config.Jwt)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(
+		&entity.OAuthClient{},
+		&entity.RefreshToken{},
+		&entity.Session{},
+		&entity.RevokedToken{},
+		&entity.AuditEvent{},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	service := oauth.NewService(
+		oauth.NewRepository(db),
+		auth.NewRefreshTokenRepository(db),
+		session.NewRepository(db),
+		auth.NewSQLRevocationStore(db),
+		audit.NewService(audit.NewRepository(db)),
+	)
+
+	app := fiber.New(fiber.Config{ErrorHandler: common.ErrorHandler})
+	oauth.NewHttpHandler(app.Group("/api/v1/oauth"), service, nil)
+
+	return &oauthTest{app: app, db: db}
+}
+
+// createClient registers a client with the given scopes and returns its ID and secret.
+func (ot *oauthTest) createClient(t *testing.T, clientID string, scopes ...string) (*entity.OAuthClient, string) {
+	t.Helper()
+
+	secret := "gfcs_" + clientID
+	client := &entity.OAuthClient{
+		Name:       clientID,
+		ClientID:   clientID,
+		SecretHash: utils.HashToken(secret),
+		Scopes:     strings.Join(scopes, ","),
+	}
+	if err := ot.db.Create(client).Error; err != nil {
+		t.Fatal(err)
+	}
+	return client, secret
+}
+
+// post sends the form to the endpoint, with the client credentials in the Authorization header
+// unless clientID is empty, and decodes the JSON response into data.
+func (ot *oauthTest) post(t *testing.T, path, clientID, secret string, form url.Values, data any) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+	if clientID != "" {
+		credentials := url.QueryEscape(clientID) + ":" + url.QueryEscape(secret)
+		req.Header.Set(fiber.HeaderAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+
+	resp, err := ot.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != nil {
+		if err := json.NewDecoder(resp.Body).Decode(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp
+}
+
+func TestTokenIssuesClientCredentialsTokens(t *testing.T) {
+	ot := newOAuthTest(t)
+	_, secret := ot.createClient(t, "gfc_reports", "reports:read", "reports:write")
+	revoked, revokedSecret := ot.createClient(t, "gfc_revoked", "reports:read")
+	now := time.Now()
+	if err := ot.db.Model(revoked).Update("revoked_at", &now).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		clientID  string
+		secret    string
+		form      url.Values
+		want      int
+		wantError string
+		wantScope string
+	}{
+		{"all scopes", "gfc_reports", secret, url.Values{"grant_type": {"client_credentials"}}, fiber.StatusOK, "", "reports:read reports:write"},
+		{"some scopes", "gfc_reports", secret, url.Values{"grant_type": {"client_credentials"}, "scope": {"reports:read"}}, fiber.StatusOK, "", "reports:read"},
+		{"credentials in the form", "", "", url.Values{"grant_type": {"client_credentials"}, "client_id": {"gfc_reports"}, "client_secret": {secret}}, fiber.StatusOK, "", "reports:read reports:write"},
+		{"scope of another client", "gfc_reports", secret, url.Values{"grant_type": {"client_credentials"}, "scope": {"users:read"}}, fiber.StatusBadRequest, "invalid_scope", ""},
+		{"other grant", "gfc_reports", secret, url.Values{"grant_type": {"password"}}, fiber.StatusBadRequest, "unsupported_grant_type", ""},
+		{"no grant", "gfc_reports", secret, url.Values{}, fiber.StatusBadRequest, "invalid_request", ""},
+		{"credentials sent twice", "gfc_reports", secret, url.Values{"grant_type": {"client_credentials"}, "client_id": {"gfc_reports"}, "client_secret": {secret}}, fiber.StatusBadRequest, "invalid_request", ""},
+		{"no credentials", "", "", url.Values{"grant_type": {"client_credentials"}}, fiber.StatusUnauthorized, "invalid_client", ""},
+		{"wrong secret", "gfc_reports", revokedSecret, url.Values{"grant_type": {"client_credentials"}}, fiber.StatusUnauthorized, "invalid_client", ""},
+		{"revoked client", "gfc_revoked", revokedSecret, url.Values{"grant_type": {"client_credentials"}}, fiber.StatusUnauthorized, "invalid_client", ""},
+	}
+	for _, tt := range tests {
+		var data struct {
+			dto.OAuthTokenResponse
+			dto.OAuthErrorResponse
+		}
+		resp := ot.post(t, "/api/v1/oauth/token", tt.clientID, tt.secret, tt.form, &data)
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, resp.StatusCode, tt.want)
+			continue
+		}
+		if resp.Header.Get(fiber.HeaderCacheControl) != "no-store" {
+			t.Errorf("%s: Cache-Control = %q, want no-store", tt.name, resp.Header.Get(fiber.HeaderCacheControl))
+		}
+		if data.Error != tt.wantError {
+			t.Errorf("%s: error = %q, want %q", tt.name, data.Error, tt.wantError)
+		}
+		if tt.want != fiber.StatusOK {
+			continue
+		}
+		if data.TokenType != "Bearer" || data.Scope != tt.wantScope {
+			t.Errorf("%s: token type = %q, scope = %q, want Bearer and %q", tt.name, data.TokenType, data.Scope, tt.wantScope)
+		}
+		claims, err := xjwt.VerifyToken(data.AccessToken)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if claims.Type != string(xjwt.TokenTypeClient) || claims.ClientID != "gfc_reports" {
+			t.Errorf("%s: token type = %q, client = %q", tt.name, claims.Type, claims.ClientID)
+		}
+	}
+}
+
+func TestIntrospectDescribesActiveTokensOnly(t *testing.T) {
+	ot := newOAuthTest(t)
+	_, secret := ot.createClient(t, "gfc_gateway", constant.ScopeTokenIntrospect)
+	_, reportsSecret := ot.createClient(t, "gfc_reports", "reports:read")
+
+	var issued dto.OAuthTokenResponse
+	ot.post(t, "/api/v1/oauth/token", "gfc_reports", reportsSecret, url.Values{"grant_type": {"client_credentials"}}, &issued)
+
+	user := &entity.User{Email: "user@example.com"}
+	user.ID = 1
+	session := &entity.Session{UserID: user.ID, Family: "family", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := ot.db.Create(session).Error; err != nil {
+		t.Fatal(err)
+	}
+	accessToken, err := xjwt.GenerateToken(user, xjwt.TokenTypeAccess, xjwt.WithFamily("family"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfaToken, err := xjwt.GenerateToken(user, xjwt.TokenTypeMfaPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	introspect := func(token string) dto.OAuthIntrospectResponse {
+		t.Helper()
+
+		var data dto.OAuthIntrospectResponse
+		resp := ot.post(t, "/api/v1/oauth/introspect", "gfc_gateway", secret, url.Values{"token": {token}}, &data)
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("introspect status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+		}
+		return data
+	}
+
+	if data := introspect(issued.AccessToken); !data.Active || data.ClientID != "gfc_reports" || data.Scope != "reports:read" {
+		t.Errorf("client token = %+v, want active for gfc_reports with reports:read", data)
+	}
+	if data := introspect(accessToken); !data.Active || data.Username != "user@example.com" || data.TokenType != string(xjwt.TokenTypeAccess) {
+		t.Errorf("access token = %+v, want active for user@example.com", data)
+	}
+
+	// Tokens that are not active give nothing away
+	if err := ot.db.Model(session).Update("revoked_at", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+	for name, token := range map[string]string{
+		"access token of a revoked session": accessToken,
+		"MFA token":                         mfaToken,
+		"malformed token":                   "not-a-token",
+	} {
+		described, err := json.Marshal(introspect(token))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(described) != `{"active":false}` {
+			t.Errorf("%s = %s, want only active false", name, described)
+		}
+	}
+
+	// Only clients with the introspection scope may ask
+	var data dto.OAuthErrorResponse
+	resp := ot.post(t, "/api/v1/oauth/introspect", "gfc_reports", reportsSecret, url.Values{"token": {issued.AccessToken}}, &data)
+	if resp.StatusCode != fiber.StatusForbidden || data.Error != "unauthorized_client" {
+		t.Errorf("introspection without the scope: status = %d, error = %q", resp.StatusCode, data.Error)
+	}
+}
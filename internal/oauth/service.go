@@ -0,0 +1,371 @@
+package oauth
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// clientIDPrefix and secretPrefix make our credentials recognizable, e.g. by secret scanners.
+const (
+	clientIDPrefix = "gfc_"
+	secretPrefix   = "gfcs_"
+)
+
+// tokenError is an error response of the token endpoint as described by RFC 6749, section 5.2.
+type tokenError struct {
+	status      int
+	code        string
+	description string
+}
+
+func (e *tokenError) Error() string {
+	return e.code + ": " + e.description
+}
+
+func newTokenError(status int, code, description string) *tokenError {
+	return &tokenError{status: status, code: code, description: description}
+}
+
+type service struct {
+	clientRepo       interfaces.OAuthClientRepository
+	refreshTokenRepo interfaces.RefreshTokenRepository
+	sessionRepo      interfaces.SessionRepository
+	revocationStore  interfaces.TokenRevocationStore
+	auditService     interfaces.AuditService
+}
+
+func (s *service) Create(c *fiber.Ctx, req *dto.CreateOAuthClientRequest) (*dto.CreateOAuthClientResponse, error) {
+	// Scopes are stored separated by commas and requested separated by spaces
+	for _, scope := range req.Scopes {
+		if strings.ContainsAny(scope, " ,") {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid scope: "+scope)
+		}
+	}
+
+	clientID, err := utils.GenerateRandomToken(12)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	secret = secretPrefix + secret
+
+	client := &entity.OAuthClient{
+		Name:       req.Name,
+		ClientID:   clientIDPrefix + clientID,
+		SecretHash: utils.HashToken(secret),
+		Scopes:     strings.Join(slices.Compact(slices.Sorted(slices.Values(req.Scopes))), ","),
+	}
+	if err := s.clientRepo.Create(client); err != nil {
+		return nil, err
+	}
+
+	clientDto := constructOAuthClientDto(client)
+	s.audit(c, constant.AuditActionOAuthClientCreated, client.ID, nil, clientDto)
+
+	return &dto.CreateOAuthClientResponse{
+		OAuthClientDto: *clientDto,
+		ClientSecret:   secret,
+	}, nil
+}
+
+func (s *service) FindAll(c *fiber.Ctx) ([]dto.OAuthClientDto, error) {
+	clients, err := s.clientRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	clientDtos := make([]dto.OAuthClientDto, 0, len(clients))
+	for _, client := range clients {
+		clientDtos = append(clientDtos, *constructOAuthClientDto(&client))
+	}
+
+	return clientDtos, nil
+}
+
+func (s *service) Revoke(c *fiber.Ctx, id uint) error {
+	revoked, err := s.clientRepo.Revoke(id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return fiber.NewError(fiber.StatusNotFound, "OAuth client not found")
+	}
+
+	now := time.Now()
+	s.audit(c, constant.AuditActionOAuthClientRevoked, id, map[string]*string{"revoked_at": nil}, map[string]*string{"revoked_at": formatTime(&now)})
+	return nil
+}
+
+func (s *service) Token(c *fiber.Ctx, req *dto.OAuthTokenRequest) (*dto.OAuthTokenResponse, error) {
+	clientID, secret, err := clientCredentials(c, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GrantType == "" {
+		return nil, newTokenError(fiber.StatusBadRequest, "invalid_request", "grant_type is required")
+	}
+
+	client, err := s.authenticate(clientID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GrantType != constant.GrantTypeClientCredentials {
+		return nil, newTokenError(fiber.StatusBadRequest, "unsupported_grant_type", "Only the client_credentials grant is supported")
+	}
+
+	scopes := client.ScopeList()
+	if req.Scope != "" {
+		scopes = strings.Fields(req.Scope)
+		for _, scope := range scopes {
+			if !slices.Contains(client.ScopeList(), scope) {
+				return nil, newTokenError(fiber.StatusBadRequest, "invalid_scope", "Scope not allowed for the client: "+scope)
+			}
+		}
+		scopes = slices.Compact(slices.Sorted(slices.Values(scopes)))
+	}
+
+	claims := xjwt.NewClientClaims(client.ClientID, scopes)
+	accessToken, err := xjwt.SignClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.clientRepo.Touch(client.ID); err != nil {
+		log.Error().Err(err).Uint("oauth_client_id", client.ID).Msg("Failed to update OAuth client last use")
+	}
+
+	return &dto.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(claims.ExpiresAt.Time).Round(time.Second).Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+func (s *service) Introspect(c *fiber.Ctx, req *dto.OAuthIntrospectRequest) (*dto.OAuthIntrospectResponse, error) {
+	clientID, secret, err := clientCredentials(c, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.authenticate(clientID, secret)
+	if err != nil {
+		return nil, err
+	}
+	// Introspection discloses who the tokens of our users belong to
+	if !slices.Contains(client.ScopeList(), constant.ScopeTokenIntrospect) {
+		return nil, newTokenError(fiber.StatusForbidden, "unauthorized_client", "The client is not allowed to introspect tokens")
+	}
+
+	if req.Token == "" {
+		return nil, newTokenError(fiber.StatusBadRequest, "invalid_request", "token is required")
+	}
+
+	// Tokens that are malformed, expired, not ours or revoked are all just inactive (RFC 7662, section 2.2)
+	claims, err := xjwt.VerifyToken(req.Token)
+	if err != nil {
+		return &dto.OAuthIntrospectResponse{Active: false}, nil
+	}
+	active, err := s.isActive(claims)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return &dto.OAuthIntrospectResponse{Active: false}, nil
+	}
+
+	return constructIntrospectResponse(claims), nil
+}
+
+func (s *service) Validate(c *fiber.Ctx, clientID string) error {
+	client, err := s.clientRepo.FindByClientID(clientID)
+	if err != nil || client.RevokedAt != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired JWT")
+	}
+
+	return nil
+}
+
+// authenticate finds the client with the given credentials, as long as it has not been revoked.
+func (s *service) authenticate(clientID, secret string) (*entity.OAuthClient, error) {
+	client, _ := s.clientRepo.FindByClientID(clientID)
+	if client == nil || client.RevokedAt != nil ||
+		subtle.ConstantTimeCompare([]byte(utils.HashToken(secret)), []byte(client.SecretHash)) != 1 {
+		return nil, newTokenError(fiber.StatusUnauthorized, "invalid_client", "Client authentication failed")
+	}
+
+	return client, nil
+}
+
+// isActive applies the same revocation checks to the token as the middlewares accepting it.
+func (s *service) isActive(claims *xjwt.TokenClaims) (bool, error) {
+	// Every token we issue carries both, so anything else was not issued by us
+	if claims.IssuedAt == nil || claims.ExpiresAt == nil {
+		return false, nil
+	}
+
+	switch xjwt.TokenType(claims.Type) {
+	case xjwt.TokenTypeClient:
+		client, err := s.clientRepo.FindByClientID(claims.ClientID)
+		return err == nil && client.RevokedAt == nil, nil
+	case xjwt.TokenTypeRefresh:
+		// Refresh tokens are rotated, so one that has been used is no good anymore
+		stored, err := s.refreshTokenRepo.FindByTokenID(claims.ID)
+		return err == nil && stored.UsedAt == nil && stored.RevokedAt == nil, nil
+	case xjwt.TokenTypeAccess:
+	default:
+		// The other tokens only stand for a step of a flow, such as a login waiting for its second
+		// factor, and do not grant access to anything a resource server could serve
+		return false, nil
+	}
+
+	revoked, err := s.revocationStore.IsRevoked(claims.ID, claims.UserID(), claims.IssuedAt.Time)
+	if err != nil || revoked {
+		return false, err
+	}
+	if claims.Impersonated() {
+		revoked, err := s.revocationStore.IsRevoked("", claims.ActorID(), claims.IssuedAt.Time)
+		if err != nil || revoked {
+			return false, err
+		}
+	}
+	if claims.Family != "" {
+		session, err := s.sessionRepo.FindByFamily(claims.Family)
+		if err != nil || session.RevokedAt != nil {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// clientCredentials reads the credentials of the client from the Authorization header or, failing
+// that, from the form. Using both at once is not allowed (RFC 6749, section 2.3).
+func clientCredentials(c *fiber.Ctx, formClientID, formSecret string) (string, string, error) {
+	scheme, encoded, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Basic") {
+		if formClientID == "" || formSecret == "" {
+			return "", "", newTokenError(fiber.StatusUnauthorized, "invalid_client", "Client authentication is required")
+		}
+		return formClientID, formSecret, nil
+	}
+
+	if formClientID != "" || formSecret != "" {
+		return "", "", newTokenError(fiber.StatusBadRequest, "invalid_request", "Client credentials must be sent only once")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", "", newTokenError(fiber.StatusUnauthorized, "invalid_client", "Malformed client credentials")
+	}
+	rawID, rawSecret, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return "", "", newTokenError(fiber.StatusUnauthorized, "invalid_client", "Malformed client credentials")
+	}
+
+	// The credentials are form-encoded before being put in the header
+	clientID, err := url.QueryUnescape(rawID)
+	if err != nil {
+		return "", "", newTokenError(fiber.StatusUnauthorized, "invalid_client", "Malformed client credentials")
+	}
+	secret, err := url.QueryUnescape(rawSecret)
+	if err != nil {
+		return "", "", newTokenError(fiber.StatusUnauthorized, "invalid_client", "Malformed client credentials")
+	}
+
+	return clientID, secret, nil
+}
+
+// audit records a change of the OAuth client, with the fields that changed. Either value may be nil.
+func (s *service) audit(c *fiber.Ctx, action string, id uint, before, after any) {
+	record := &interfaces.AuditRecord{
+		Action:     action,
+		TargetType: constant.AuditTargetOAuthClient,
+		TargetID:   strconv.Itoa(int(id)),
+	}
+
+	diff, err := utils.Diff(before, after, "created_at", "last_used_at")
+	if err != nil {
+		log.Error().Err(err).Uint("oauth_client_id", id).Msg("Failed to compute OAuth client diff")
+	} else {
+		record.Diff = diff
+	}
+
+	s.auditService.Record(c, record)
+}
+
+func constructOAuthClientDto(client *entity.OAuthClient) *dto.OAuthClientDto {
+	return &dto.OAuthClientDto{
+		ID:         client.ID,
+		Name:       client.Name,
+		ClientID:   client.ClientID,
+		Scopes:     client.ScopeList(),
+		LastUsedAt: formatTime(client.LastUsedAt),
+		RevokedAt:  formatTime(client.RevokedAt),
+		CreatedAt:  client.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func constructIntrospectResponse(claims *xjwt.TokenClaims) *dto.OAuthIntrospectResponse {
+	response := &dto.OAuthIntrospectResponse{
+		Active:    true,
+		Scope:     strings.Join(claims.Scopes, " "),
+		ClientID:  claims.ClientID,
+		Username:  claims.UserEmail,
+		TokenType: claims.Type,
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		IssuedAt:  claims.IssuedAt.Unix(),
+		Subject:   claims.Subject,
+		Issuer:    claims.Issuer,
+		TokenID:   claims.ID,
+		Roles:     claims.Roles,
+	}
+	if claims.Actor != nil {
+		response.Actor = &dto.OAuthTokenActor{Subject: claims.Actor.Subject, Email: claims.Actor.Email}
+	}
+
+	return response
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format("2006-01-02 15:04:05")
+	return &formatted
+}
+
+func NewService(
+	clientRepo interfaces.OAuthClientRepository,
+	refreshTokenRepo interfaces.RefreshTokenRepository,
+	sessionRepo interfaces.SessionRepository,
+	revocationStore interfaces.TokenRevocationStore,
+	auditService interfaces.AuditService,
+) interfaces.OAuthClientService {
+	return &service{
+		clientRepo:       clientRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		revocationStore:  revocationStore,
+		auditService:     auditService,
+	}
+}
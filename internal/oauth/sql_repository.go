@@ -0,0 +1,51 @@
+package oauth
+
+import (
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func (r *repository) Create(data *entity.OAuthClient) error {
+	return r.db.Create(data).Error
+}
+
+func (r *repository) FindByClientID(clientID string) (*entity.OAuthClient, error) {
+	var client entity.OAuthClient
+	if err := r.db.Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *repository) FindAll() ([]entity.OAuthClient, error) {
+	var clients []entity.OAuthClient
+	if err := r.db.Order("created_at DESC").Find(&clients).Error; err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+func (r *repository) Revoke(id uint) (bool, error) {
+	result := r.db.Model(&entity.OAuthClient{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) Touch(id uint) error {
+	return r.db.Model(&entity.OAuthClient{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
+}
+
+func NewRepository(db *gorm.DB) interfaces.OAuthClientRepository {
+	return &repository{db: db}
+}
@@ -3,6 +3,7 @@ package product
 import (
 	"go-fiber-template/internal/domain/dto"
 	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/constant"
 	"go-fiber-template/lib/middleware"
 	"go-fiber-template/lib/utils"
 	"strconv"
@@ -19,11 +20,14 @@ func NewHttpHandler(r fiber.Router, productService interfaces.ProductService) {
 		productService: productService,
 	}
 
-	r.Post("/", middleware.Validate[dto.CreateProductRequest](), handler.Create)
-	r.Get("/", handler.FindAll)
+	protected := middleware.ProtectedWithAPIKey()
+	canWrite := middleware.RequirePermission(constant.PermissionProductWrite)
+
+	r.Post("/", protected, canWrite, middleware.Validate[dto.CreateProductRequest](), handler.Create)
+	r.Get("/", middleware.ValidateQuery[dto.ProductFilter](), handler.FindAll)
 	r.Get("/:id", handler.FindByID)
-	r.Put("/:id", middleware.Validate[dto.UpdateProductRequest](), handler.Update)
-	r.Delete("/:id", handler.Delete)
+	r.Put("/:id", protected, canWrite, middleware.Validate[dto.UpdateProductRequest](), handler.Update)
+	r.Delete("/:id", protected, canWrite, handler.Delete)
 }
 
 func (h *httpHandler) Create(c *fiber.Ctx) error {
@@ -40,15 +44,22 @@ func (h *httpHandler) Create(c *fiber.Ctx) error {
 }
 
 func (h *httpHandler) FindAll(c *fiber.Ctx) error {
-	products, err := h.productService.FindAll(c)
+	filter := utils.ExtractStructFromValidator[dto.ProductFilter](c)
+	products, page, err := h.productService.FindAll(c, filter)
 	if err != nil {
 		return err
 	}
 
-	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+	response := dto.ResponseDto{
 		Message: "Products fetched successfully",
 		Data:    products,
-	})
+	}
+	if page != nil {
+		response.NextCursor = page.NextCursor
+		response.PrevCursor = page.PrevCursor
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
 }
 
 func (h *httpHandler) FindByID(c *fiber.Ctx) error {
@@ -0,0 +1,62 @@
+package product_test
+
+import (
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/product"
+	"go-fiber-template/lib/common"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/xvalidator"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cache"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestFindAllKeepsPaginationHeadersOnCacheHits(t *testing.T) {
+	xvalidator.Setup()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.Product{}); err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		if err := db.Create(&entity.Product{Name: "product", Description: "product", Price: 1, Stock: 1}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: common.ErrorHandler})
+	app.Use(cache.New(config.CacheCfg))
+	product.NewHttpHandler(app.Group("/api/v1/products"), product.NewService(product.NewRepository(db, []byte("secret")), nil))
+
+	want := map[string]string{
+		constant.HeaderXTotalCount: "5",
+		constant.HeaderXTotalPages: "3",
+		constant.HeaderXPage:       "2",
+		constant.HeaderXLimit:      "2",
+		constant.HeaderXNextPage:   "3",
+		constant.HeaderXPrevPage:   "1",
+	}
+	for i, cached := range []string{"miss", "hit"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/products?page=2&limit=2", nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Header.Get("X-Cache") != cached {
+			t.Fatalf("request %d: X-Cache = %q, want %q", i+1, resp.Header.Get("X-Cache"), cached)
+		}
+		for header, value := range want {
+			if resp.Header.Get(header) != value {
+				t.Errorf("request %d: %s = %q, want %q", i+1, header, resp.Header.Get(header), value)
+			}
+		}
+	}
+}
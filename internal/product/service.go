@@ -1,20 +1,29 @@
 package product
 
 import (
+	"errors"
 	"go-fiber-template/internal/domain/dto"
 	"go-fiber-template/internal/domain/entity"
 	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/database"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
 )
 
 type service struct {
-	productRepo interfaces.ProductRepository
+	productRepo  interfaces.ProductRepository
+	auditService interfaces.AuditService
 }
 
 // Create implements interfaces.ProductService.
 func (s *service) Create(c *fiber.Ctx, req *dto.CreateProductRequest) (*dto.ProductDto, error) {
 	product := &entity.Product{
+		OwnerID:     ownerID(c),
 		Name:        req.Name,
 		Description: req.Description,
 		Price:       req.Price,
@@ -25,31 +34,67 @@ func (s *service) Create(c *fiber.Ctx, req *dto.CreateProductRequest) (*dto.Prod
 		return nil, err
 	}
 
-	return constructProductDto(product), nil
+	productDto := constructProductDto(product)
+	s.audit(c, constant.AuditActionProductCreated, product.ID, nil, productDto)
+	return productDto, nil
 }
 
 // Delete implements interfaces.ProductService.
 func (s *service) Delete(c *fiber.Ctx, id uint) error {
+	// Deleting a product that does not exist succeeds, but there is nothing to audit then
+	product, _ := s.productRepo.FindByID(id)
+
 	if err := s.productRepo.Delete(id); err != nil {
 		return err
 	}
 
+	if product != nil {
+		s.audit(c, constant.AuditActionProductDeleted, id, constructProductDto(product), nil)
+	}
 	return nil
 }
 
 // FindAll implements interfaces.ProductService.
-func (s *service) FindAll(c *fiber.Ctx) ([]dto.ProductDto, error) {
-	products, err := s.productRepo.FindAll()
+func (s *service) FindAll(c *fiber.Ctx, filter *dto.ProductFilter) ([]dto.ProductDto, *dto.CursorPageDto, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
+	if filter.Cursor != nil {
+		return s.findAllByCursor(c, filter)
+	}
+
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Sort == "" {
+		// Newest first unless asked otherwise
+		filter.Sort = "-created_at"
+	}
+
+	products, total, err := s.productRepo.FindAll(filter)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var productDtos []dto.ProductDto
-	for _, product := range products {
-		productDtos = append(productDtos, *constructProductDto(&product))
+	utils.SetPaginationHeader(c, filter.Page, filter.Limit, int(total))
+	return constructProductDtos(products), nil, nil
+}
+
+// findAllByCursor lists a page of products after the cursor of the filter, without counting them.
+func (s *service) findAllByCursor(c *fiber.Ctx, filter *dto.ProductFilter) ([]dto.ProductDto, *dto.CursorPageDto, error) {
+	products, page, err := s.productRepo.FindAllByCursor(filter)
+	if errors.Is(err, database.ErrInvalidCursor) {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid cursor")
+	}
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return productDtos, nil
+	utils.SetCursorLinkHeader(c, page.NextCursor, page.PrevCursor)
+	return constructProductDtos(products), &dto.CursorPageDto{
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}, nil
 }
 
 // FindByID implements interfaces.ProductService.
@@ -68,6 +113,7 @@ func (s *service) Update(c *fiber.Ctx, id uint, req *dto.UpdateProductRequest) (
 	if err != nil {
 		return nil, err
 	}
+	before := constructProductDto(product)
 
 	product.Name = req.Name
 	product.Description = req.Description
@@ -78,12 +124,42 @@ func (s *service) Update(c *fiber.Ctx, id uint, req *dto.UpdateProductRequest) (
 		return nil, err
 	}
 
-	return constructProductDto(product), nil
+	productDto := constructProductDto(product)
+	s.audit(c, constant.AuditActionProductUpdated, product.ID, before, productDto)
+	return productDto, nil
+}
+
+// audit records a change to a product along with the fields that changed. Either side is nil
+// for products that were created or deleted.
+func (s *service) audit(c *fiber.Ctx, action string, id uint, before, after *dto.ProductDto) {
+	record := &interfaces.AuditRecord{
+		Action:     action,
+		TargetType: constant.AuditTargetProduct,
+		TargetID:   strconv.Itoa(int(id)),
+	}
+
+	diff, err := utils.Diff(before, after, "created_at", "updated_at")
+	if err != nil {
+		log.Error().Err(err).Uint("product_id", id).Msg("Failed to compute product diff")
+	} else {
+		record.Diff = diff
+	}
+
+	s.auditService.Record(c, record)
+}
+
+func constructProductDtos(products []entity.Product) []dto.ProductDto {
+	productDtos := make([]dto.ProductDto, 0, len(products))
+	for _, product := range products {
+		productDtos = append(productDtos, *constructProductDto(&product))
+	}
+	return productDtos
 }
 
 func constructProductDto(product *entity.Product) *dto.ProductDto {
 	return &dto.ProductDto{
 		ID:          product.ID,
+		OwnerID:     product.OwnerID,
 		Name:        product.Name,
 		Description: product.Description,
 		Price:       product.Price,
@@ -93,6 +169,19 @@ func constructProductDto(product *entity.Product) *dto.ProductDto {
 	}
 }
 
-func NewService(productRepo interfaces.ProductRepository) interfaces.ProductService {
-	return &service{productRepo: productRepo}
+// ownerID returns the user the request was authenticated as, with an access token or an API key.
+func ownerID(c *fiber.Ctx) *uint {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return nil
+	}
+	id := claims.UserID()
+	return &id
+}
+
+func NewService(productRepo interfaces.ProductRepository, auditService interfaces.AuditService) interfaces.ProductService {
+	return &service{
+		productRepo:  productRepo,
+		auditService: auditService,
+	}
 }
@@ -1,14 +1,20 @@
 package product
 
 import (
+	"encoding/json"
+	"go-fiber-template/internal/domain/dto"
 	"go-fiber-template/internal/domain/entity"
 	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/database"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type repository struct {
-	db *gorm.DB
+	db           *gorm.DB
+	cursorSecret []byte
 }
 
 // Create implements interfaces.ProductRepository.
@@ -22,9 +28,77 @@ func (r *repository) Delete(id uint) error {
 }
 
 // FindAll implements interfaces.ProductRepository.
-func (r *repository) FindAll() ([]entity.Product, error) {
+func (r *repository) FindAll(filter *dto.ProductFilter) ([]entity.Product, int64, error) {
+	query := r.filter(filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// The fields were checked by the validator, and the ID breaks ties so that pages do not overlap
+	for _, field := range strings.Split(filter.Sort, ",") {
+		query = query.Order(clause.OrderByColumn{
+			Column: clause.Column{Name: strings.TrimPrefix(field, "-")},
+			Desc:   strings.HasPrefix(field, "-"),
+		})
+	}
+
+	var products []entity.Product
+	err := query.Order("id").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+// FindAllByCursor implements interfaces.ProductRepository.
+func (r *repository) FindAllByCursor(filter *dto.ProductFilter) ([]entity.Product, *database.CursorPage, error) {
+	return database.Paginate(r.filter(filter), r.cursorSecret, cursorScope(filter), *filter.Cursor, filter.Limit, func(product *entity.Product) database.Cursor {
+		return database.Cursor{CreatedAt: product.CreatedAt, ID: product.ID}
+	})
+}
+
+// cursorScope describes the listing a cursor is handed out for, every field of the filter but the
+// ones that move through it.
+func cursorScope(filter *dto.ProductFilter) string {
+	scope := *filter
+	scope.Cursor, scope.Page, scope.Limit = nil, 0, 0
+	payload, _ := json.Marshal(scope)
+	return string(payload)
+}
+
+// filter selects the products matching the filter.
+func (r *repository) filter(filter *dto.ProductFilter) *gorm.DB {
+	query := r.db.Model(&entity.Product{})
+	if filter.Name != "" {
+		query = query.Where(database.Contains("name", filter.Name))
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+	if filter.InStock != nil {
+		if *filter.InStock {
+			query = query.Where("stock > 0")
+		} else {
+			query = query.Where("stock <= 0")
+		}
+	}
+
+	return query
+}
+
+// FindByOwnerID implements interfaces.ProductRepository.
+func (r *repository) FindByOwnerID(ownerID uint) ([]entity.Product, error) {
 	var products []entity.Product
-	if err := r.db.Find(&products).Error; err != nil {
+	if err := r.db.Where("owner_id = ?", ownerID).Find(&products).Error; err != nil {
 		return nil, err
 	}
 	return products, nil
@@ -44,6 +118,6 @@ func (r *repository) Update(data *entity.Product) error {
 	return r.db.Save(data).Error
 }
 
-func NewRepository(db *gorm.DB) interfaces.ProductRepository {
-	return &repository{db: db}
+func NewRepository(db *gorm.DB, cursorSecret []byte) interfaces.ProductRepository {
+	return &repository{db: db, cursorSecret: cursorSecret}
 }
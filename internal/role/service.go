@@ -0,0 +1,179 @@
+package role
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type service struct {
+	roleRepo        interfaces.RoleRepository
+	userRepo        interfaces.UserRepository
+	revocationStore interfaces.TokenRevocationStore
+	auditService    interfaces.AuditService
+}
+
+func (s *service) FindAll(c *fiber.Ctx) ([]dto.RoleDto, error) {
+	roles, err := s.roleRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	roleDtos := make([]dto.RoleDto, 0, len(roles))
+	for _, role := range roles {
+		roleDtos = append(roleDtos, *constructRoleDto(&role))
+	}
+
+	return roleDtos, nil
+}
+
+func (s *service) AssignToUser(c *fiber.Ctx, userID uint, req *dto.AssignRoleRequest) error {
+	user, role, err := s.findUserAndRole(userID, req.Role)
+	if err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.AssignToUser(userID, role); err != nil {
+		return err
+	}
+
+	after := user.RoleNames()
+	if !slices.Contains(after, role.Name) {
+		after = append(after, role.Name)
+	}
+	return s.rolesChanged(c, constant.AuditActionUserRoleAssigned, userID, user.RoleNames(), after)
+}
+
+func (s *service) RemoveFromUser(c *fiber.Ctx, userID uint, roleName string) error {
+	user, role, err := s.findUserAndRole(userID, roleName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.RemoveFromUser(userID, role); err != nil {
+		return err
+	}
+
+	after := slices.DeleteFunc(user.RoleNames(), func(name string) bool { return name == role.Name })
+	return s.rolesChanged(c, constant.AuditActionUserRoleRemoved, userID, user.RoleNames(), after)
+}
+
+func (s *service) SetUserRoles(c *fiber.Ctx, userID uint, req *dto.SetRolesRequest) error {
+	user, _ := s.userRepo.FindByID(userID)
+	if user == nil {
+		return fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	roles := make([]entity.Role, 0, len(req.Roles))
+	for _, roleName := range req.Roles {
+		role, _ := s.roleRepo.FindByName(roleName)
+		if role == nil {
+			return fiber.NewError(fiber.StatusNotFound, "role not found: "+roleName)
+		}
+		roles = append(roles, *role)
+	}
+
+	if err := s.roleRepo.ReplaceForUser(userID, roles); err != nil {
+		return err
+	}
+
+	after := make([]string, 0, len(roles))
+	for _, role := range roles {
+		after = append(after, role.Name)
+	}
+	return s.rolesChanged(c, constant.AuditActionUserRolesSet, userID, user.RoleNames(), after)
+}
+
+func (s *service) HasPermission(c *fiber.Ctx, roleNames []string, permission string) (bool, error) {
+	if len(roleNames) == 0 {
+		return false, nil
+	}
+
+	permissions, err := s.roleRepo.FindPermissionNames(roleNames)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(permissions, permission), nil
+}
+
+func (s *service) findUserAndRole(userID uint, roleName string) (*entity.User, *entity.Role, error) {
+	user, _ := s.userRepo.FindByID(userID)
+	if user == nil {
+		return nil, nil, fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	role, _ := s.roleRepo.FindByName(roleName)
+	if role == nil {
+		return nil, nil, fiber.NewError(fiber.StatusNotFound, "role not found")
+	}
+
+	return user, role, nil
+}
+
+// rolesChanged revokes the access tokens of the user, which carry the roles they were issued with,
+// and audits the change. Refreshing the session issues tokens with the new roles.
+func (s *service) rolesChanged(c *fiber.Ctx, action string, userID uint, before, after []string) error {
+	if err := s.revocationStore.RevokeUser(userID, time.Now().Add(xjwt.TTL(xjwt.TokenTypeAccess))); err != nil {
+		return err
+	}
+
+	s.audit(c, action, userID, before, after)
+	return nil
+}
+
+// audit records a change of the roles of the user, with the role names before and after it.
+func (s *service) audit(c *fiber.Ctx, action string, userID uint, before, after []string) {
+	record := &interfaces.AuditRecord{
+		Action:     action,
+		TargetType: constant.AuditTargetUser,
+		TargetID:   strconv.Itoa(int(userID)),
+	}
+
+	slices.Sort(before)
+	slices.Sort(after)
+	diff, err := utils.Diff(map[string][]string{"roles": before}, map[string][]string{"roles": after})
+	if err != nil {
+		log.Error().Err(err).Uint("user_id", userID).Msg("Failed to compute role diff")
+	} else {
+		record.Diff = diff
+	}
+
+	s.auditService.Record(c, record)
+}
+
+func constructRoleDto(role *entity.Role) *dto.RoleDto {
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+
+	return &dto.RoleDto{
+		ID:          role.ID,
+		Name:        role.Name,
+		Permissions: permissions,
+	}
+}
+
+func NewService(
+	roleRepo interfaces.RoleRepository,
+	userRepo interfaces.UserRepository,
+	revocationStore interfaces.TokenRevocationStore,
+	auditService interfaces.AuditService,
+) interfaces.RoleService {
+	return &service{
+		roleRepo:        roleRepo,
+		userRepo:        userRepo,
+		revocationStore: revocationStore,
+		auditService:    auditService,
+	}
+}
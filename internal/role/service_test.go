@@ -0,0 +1,125 @@
+package role_test
+
+import (
+	"go-fiber-template/internal/audit"
+	"go-fiber-template/internal/auth"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/internal/role"
+	"go-fiber-template/internal/user"
+	"go-fiber-template/lib/constant"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRoleChangesAreAudited(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.Permission{}, &entity.Role{}, &entity.User{}, &entity.AuditEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"admin", "editor", "user"} {
+		if err := db.Create(&entity.Role{Name: name}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	u := &entity.User{Name: "user", Email: "user@example.com", Password: "hash"}
+	if err := db.Create(u).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	revocationStore := auth.NewMemoryRevocationStore()
+	service := role.NewService(role.NewRepository(db), user.NewRepository(db), revocationStore, audit.NewService(audit.NewRepository(db)))
+
+	if err := service.AssignToUser(c, u.ID, &dto.AssignRoleRequest{Role: "user"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.SetUserRoles(c, u.ID, &dto.SetRolesRequest{Roles: []string{"editor", "admin"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.RemoveFromUser(c, u.ID, "editor"); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []entity.AuditEvent
+	if err := db.Order("id").Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ action, diff string }{
+		{constant.AuditActionUserRoleAssigned, `{"roles":{"old":[],"new":["user"]}}`},
+		{constant.AuditActionUserRolesSet, `{"roles":{"old":["user"],"new":["admin","editor"]}}`},
+		{constant.AuditActionUserRoleRemoved, `{"roles":{"old":["admin","editor"],"new":["admin"]}}`},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d audit events, want %d", len(events), len(want))
+	}
+	for i, event := range events {
+		if event.Action != want[i].action || event.TargetType != constant.AuditTargetUser || event.Diff == nil || *event.Diff != want[i].diff {
+			t.Errorf("event %d = %s %s %v, want %s %s", i+1, event.Action, event.TargetType, event.Diff, want[i].action, want[i].diff)
+		}
+	}
+}
+
+func TestRoleChangesRevokeAccessTokens(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.Permission{}, &entity.Role{}, &entity.User{}, &entity.AuditEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&entity.Role{Name: "admin"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	u := &entity.User{Name: "user", Email: "user@example.com", Password: "hash"}
+	if err := db.Create(u).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	tests := []struct {
+		name   string
+		change func(service interfaces.RoleService) error
+	}{
+		{"assign", func(service interfaces.RoleService) error {
+			return service.AssignToUser(c, u.ID, &dto.AssignRoleRequest{Role: "admin"})
+		}},
+		{"remove", func(service interfaces.RoleService) error {
+			return service.RemoveFromUser(c, u.ID, "admin")
+		}},
+		{"set", func(service interfaces.RoleService) error {
+			return service.SetUserRoles(c, u.ID, &dto.SetRolesRequest{Roles: []string{"admin"}})
+		}},
+	}
+	issuedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	for _, tt := range tests {
+		revocationStore := auth.NewMemoryRevocationStore()
+		service := role.NewService(role.NewRepository(db), user.NewRepository(db), revocationStore, audit.NewService(audit.NewRepository(db)))
+		if err := tt.change(service); err != nil {
+			t.Fatal(err)
+		}
+
+		revoked, err := revocationStore.IsRevoked("token", u.ID, issuedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !revoked {
+			t.Errorf("%s: access token issued before the role change is still valid", tt.name)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package role
+
+import (
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func (r *repository) FindAll() ([]entity.Role, error) {
+	var roles []entity.Role
+	if err := r.db.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *repository) FindByName(name string) (*entity.Role, error) {
+	var role entity.Role
+	if err := r.db.Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *repository) FindPermissionNames(roleNames []string) ([]string, error) {
+	var names []string
+	err := r.db.Model(&entity.Permission{}).
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name IN ? AND roles.deleted_at IS NULL", roleNames).
+		Pluck("permissions.name", &names).Error
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (r *repository) AssignToUser(userID uint, role *entity.Role) error {
+	return r.db.Model(&entity.User{Model: gorm.Model{ID: userID}}).Association("Roles").Append(role)
+}
+
+func (r *repository) RemoveFromUser(userID uint, role *entity.Role) error {
+	return r.db.Model(&entity.User{Model: gorm.Model{ID: userID}}).Association("Roles").Delete(role)
+}
+
+func (r *repository) ReplaceForUser(userID uint, roles []entity.Role) error {
+	user := &entity.User{Model: gorm.Model{ID: userID}}
+	if len(roles) == 0 {
+		return r.db.Model(user).Association("Roles").Clear()
+	}
+	return r.db.Model(user).Association("Roles").Replace(roles)
+}
+
+func NewRepository(db *gorm.DB) interfaces.RoleRepository {
+	return &repository{db: db}
+}
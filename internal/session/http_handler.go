@@ -0,0 +1,76 @@
+package session
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type httpHandler struct {
+	sessionService interfaces.SessionService
+}
+
+func NewHttpHandler(r fiber.Router, sessionService interfaces.SessionService) {
+	handler := &httpHandler{
+		sessionService: sessionService,
+	}
+
+	// Sessions are managed as oneself, an impersonation could otherwise log the user out everywhere
+	r.Use(middleware.Protected(), middleware.DenyImpersonation())
+	r.Get("/", handler.FindAll)
+	r.Delete("/:id", handler.Revoke)
+}
+
+// @Summary		List sessions
+// @Description	List the devices the current user is logged in on
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Success		200	{object}	dto.ResponseDto{data=[]dto.SessionDto}
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		403	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/auth/sessions [get]
+func (h *httpHandler) FindAll(c *fiber.Ctx) error {
+	data, err := h.sessionService.FindAll(c)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Sessions fetched successfully",
+		Data:    data,
+	})
+}
+
+// @Summary		Revoke session
+// @Description	Log the current user out of one of their devices
+// @Tags			Auth
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			id	path		int	true	"Session ID"
+// @Success		200	{object}	dto.ResponseDto
+// @Failure		400	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		403	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/auth/sessions/{id} [delete]
+func (h *httpHandler) Revoke(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid session ID")
+	}
+
+	if err := h.sessionService.Revoke(c, uint(id)); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Session revoked successfully",
+	})
+}
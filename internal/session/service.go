@@ -0,0 +1,130 @@
+package session
+
+import (
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// touchInterval limits how often the last activity of a session is written,
+// so that a busy client does not cause a write on every request.
+const touchInterval = time.Minute
+
+type service struct {
+	sessionRepo      interfaces.SessionRepository
+	refreshTokenRepo interfaces.RefreshTokenRepository
+	auditService     interfaces.AuditService
+}
+
+func (s *service) FindAll(c *fiber.Ctx) ([]dto.SessionDto, error) {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	sessions, err := s.sessionRepo.FindActiveByUserID(claims.UserID())
+	if err != nil {
+		return nil, err
+	}
+
+	sessionDtos := make([]dto.SessionDto, 0, len(sessions))
+	for _, session := range sessions {
+		sessionDto := constructSessionDto(&session)
+		sessionDto.Current = session.Family == claims.Family
+		sessionDtos = append(sessionDtos, *sessionDto)
+	}
+
+	return sessionDtos, nil
+}
+
+func (s *service) Revoke(c *fiber.Ctx, id uint) error {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	session, err := s.sessionRepo.FindByID(id, claims.UserID())
+	if err != nil || session.RevokedAt != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Session not found")
+	}
+
+	if err := s.sessionRepo.RevokeByFamily(session.Family); err != nil {
+		return err
+	}
+
+	if err := s.refreshTokenRepo.RevokeFamily(session.Family); err != nil {
+		return err
+	}
+
+	s.audit(c, session, time.Now())
+	return nil
+}
+
+func (s *service) Validate(c *fiber.Ctx, family string) error {
+	now := time.Now()
+	session, err := s.sessionRepo.FindByFamily(family)
+	if err != nil || session.RevokedAt != nil || !now.Before(session.ExpiresAt) {
+		return fiber.NewError(fiber.StatusUnauthorized, "Session has been revoked")
+	}
+
+	if now.Sub(session.LastSeenAt) >= touchInterval {
+		if err := s.sessionRepo.Touch(session.ID, now); err != nil {
+			log.Error().Err(err).Uint("session_id", session.ID).Msg("Failed to update session last seen")
+		}
+	}
+
+	return nil
+}
+
+func (s *service) PurgeExpired() error {
+	return s.sessionRepo.DeleteExpired(time.Now())
+}
+
+// audit records the revocation of the session, with the time it was revoked at.
+func (s *service) audit(c *fiber.Ctx, session *entity.Session, revokedAt time.Time) {
+	record := &interfaces.AuditRecord{
+		Action:     constant.AuditActionSessionRevoked,
+		TargetType: constant.AuditTargetSession,
+		TargetID:   strconv.Itoa(int(session.ID)),
+	}
+
+	after := revokedAt.Format("2006-01-02 15:04:05")
+	diff, err := utils.Diff(map[string]*string{"revoked_at": nil}, map[string]*string{"revoked_at": &after})
+	if err != nil {
+		log.Error().Err(err).Uint("session_id", session.ID).Msg("Failed to compute session diff")
+	} else {
+		record.Diff = diff
+	}
+
+	s.auditService.Record(c, record)
+}
+
+func constructSessionDto(session *entity.Session) *dto.SessionDto {
+	return &dto.SessionDto{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		CreatedAt:  session.CreatedAt.Format("2006-01-02 15:04:05"),
+		LastSeenAt: session.LastSeenAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func NewService(
+	sessionRepo interfaces.SessionRepository,
+	refreshTokenRepo interfaces.RefreshTokenRepository,
+	auditService interfaces.AuditService,
+) interfaces.SessionService {
+	return &service{
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		auditService:     auditService,
+	}
+}
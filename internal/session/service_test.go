@@ -0,0 +1,63 @@
+package session_test
+
+import (
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/session"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestExpiredSessionsAreInactive(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.Session{}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for family, expiresAt := range map[string]time.Time{"active": now.Add(time.Hour), "expired": now.Add(-time.Second)} {
+		if err := db.Create(&entity.Session{UserID: 1, Family: family, LastSeenAt: now, ExpiresAt: expiresAt}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repository := session.NewRepository(db)
+	service := session.NewService(repository, nil, nil)
+	app := fiber.New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	sessions, err := repository.FindActiveByUserID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Family != "active" {
+		t.Errorf("active sessions = %+v, want only the active one", sessions)
+	}
+
+	if err := service.Validate(c, "active"); err != nil {
+		t.Errorf("Validate(active) = %v, want nil", err)
+	}
+	if err := service.Validate(c, "expired"); err == nil {
+		t.Error("Validate(expired) = nil, want an error")
+	}
+
+	if err := service.PurgeExpired(); err != nil {
+		t.Fatal(err)
+	}
+	var families []string
+	if err := db.Unscoped().Model(&entity.Session{}).Pluck("family", &families).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 1 || families[0] != "active" {
+		t.Errorf("sessions after purge = %v, want [active]", families)
+	}
+}
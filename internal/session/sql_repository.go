@@ -0,0 +1,80 @@
+package session
+
+import (
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/domain/interfaces"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func (r *repository) Create(data *entity.Session) error {
+	return r.db.Create(data).Error
+}
+
+func (r *repository) FindByFamily(family string) (*entity.Session, error) {
+	var session entity.Session
+	if err := r.db.Where("family = ?", family).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *repository) FindActiveByUserID(userID uint) ([]entity.Session, error) {
+	var sessions []entity.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *repository) FindByUserID(userID uint) ([]entity.Session, error) {
+	var sessions []entity.Session
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *repository) FindByID(id uint, userID uint) (*entity.Session, error) {
+	var session entity.Session
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *repository) RevokeByFamily(family string) error {
+	return r.db.Model(&entity.Session{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *repository) RevokeByUserID(userID uint) error {
+	return r.db.Model(&entity.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *repository) Touch(id uint, at time.Time) error {
+	return r.db.Model(&entity.Session{}).Where("id = ?", id).Update("last_seen_at", at).Error
+}
+
+func (r *repository) Extend(family string, expiresAt time.Time) error {
+	return r.db.Model(&entity.Session{}).Where("family = ?", family).Update("expires_at", expiresAt).Error
+}
+
+func (r *repository) DeleteExpired(before time.Time) error {
+	return r.db.Unscoped().Where("expires_at < ?", before).Delete(&entity.Session{}).Error
+}
+
+func NewRepository(db *gorm.DB) interfaces.SessionRepository {
+	return &repository{db: db}
+}
@@ -3,25 +3,163 @@ package user
 import (
 	"go-fiber-template/internal/domain/dto"
 	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/constant"
 	"go-fiber-template/lib/middleware"
+	"go-fiber-template/lib/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type httpHandler struct {
 	userService interfaces.UserService
+	authService interfaces.AuthService
 }
 
-func NewHttpHandler(r fiber.Router, userService interfaces.UserService) {
+func NewHttpHandler(r fiber.Router, userService interfaces.UserService, authService interfaces.AuthService) {
 	handler := &httpHandler{
 		userService: userService,
+		authService: authService,
 	}
 
-	r.Get("/:id", middleware.Protected(), handler.FindByID)
+	protected := middleware.Protected()
+	notImpersonated := middleware.DenyImpersonation()
+	r.Get("/me", protected, handler.FindMe)
+	r.Patch("/me", protected, middleware.Validate[dto.UpdateProfileRequest](), handler.UpdateMe)
+	r.Post("/me/password", protected, notImpersonated, middleware.Validate[dto.ChangePasswordRequest](), handler.ChangePassword)
+	r.Post("/me/email", protected, notImpersonated, middleware.Validate[dto.ChangeEmailRequest](), handler.ChangeEmail)
+	r.Delete("/me", protected, notImpersonated, middleware.Validate[dto.DeleteAccountRequest](), handler.DeleteMe)
+	r.Get("/:id", protected, middleware.RequireVerifiedEmail(), middleware.RequireSelfOrPermission("id", constant.PermissionUserRead), handler.FindByID)
+}
+
+// @Summary		Get current user
+// @Description	Get the profile of the current user
+// @Tags			User
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Success		200	{object}	dto.ResponseDto{data=dto.UserDto}
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		404	{object}	dto.ResponseDto
+// @Failure		500	{object}	dto.ResponseDto
+// @Router			/users/me [get]
+func (h *httpHandler) FindMe(c *fiber.Ctx) error {
+	data, err := h.userService.FindMe(c)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "User found",
+		Data:    data,
+	})
+}
+
+// @Summary		Update current user
+// @Description	Update the profile of the current user. The email address is changed through /users/me/email.
+// @Tags			User
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			request	body		dto.UpdateProfileRequest	true	"Update profile request"
+// @Success		200		{object}	dto.ResponseDto{data=dto.UserDto}
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		404		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/users/me [patch]
+func (h *httpHandler) UpdateMe(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.UpdateProfileRequest](c)
+	data, err := h.userService.UpdateMe(c, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "User updated successfully",
+		Data:    data,
+	})
+}
+
+// @Summary		Change password
+// @Description	Change the password of the current user. Every other device is logged out and new tokens are returned.
+// @Tags			User
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			request	body		dto.ChangePasswordRequest	true	"Change password request"
+// @Success		200		{object}	dto.ResponseDto{data=dto.LoginResponse}
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/users/me/password [post]
+func (h *httpHandler) ChangePassword(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.ChangePasswordRequest](c)
+	data, err := h.authService.ChangePassword(c, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "Password changed successfully",
+		Data:    data,
+	})
+}
+
+// @Summary		Change email
+// @Description	Request a change of the email address of the current user. The new address replaces the current one once it is verified through the link sent to it.
+// @Tags			User
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			request	body		dto.ChangeEmailRequest	true	"Change email request"
+// @Success		202		{object}	dto.ResponseDto
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		409		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/users/me/email [post]
+func (h *httpHandler) ChangeEmail(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.ChangeEmailRequest](c)
+	if err := h.authService.ChangeEmail(c, req); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(dto.ResponseDto{
+		Message: "Verification email sent to the new address",
+	})
+}
+
+// @Summary		Delete current user
+// @Description	Delete the account of the current user. Logging in again during the grace period restores it, afterwards its personal data is anonymized.
+// @Tags			User
+// @Accept			application/json
+// @Produce		application/json
+// @Security		Bearer
+// @Param			request	body		dto.DeleteAccountRequest	true	"Delete account request"
+// @Success		200		{object}	dto.ResponseDto{data=dto.DeleteAccountResponse}
+// @Failure		400		{object}	dto.ResponseDto
+// @Failure		401		{object}	dto.ResponseDto
+// @Failure		422		{object}	dto.ResponseDto
+// @Failure		500		{object}	dto.ResponseDto
+// @Router			/users/me [delete]
+func (h *httpHandler) DeleteMe(c *fiber.Ctx) error {
+	req := utils.ExtractStructFromValidator[dto.DeleteAccountRequest](c)
+	data, err := h.authService.DeleteAccount(c, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ResponseDto{
+		Message: "User deleted successfully",
+		Data:    data,
+	})
 }
 
 // @Summary		Find user by ID
-// @Description	Find user by ID
+// @Description	Find user by ID. Users can only find themselves unless they have the user:read permission.
 // @Tags			User
 // @Accept			application/json
 // @Produce		application/json
@@ -29,6 +167,8 @@ func NewHttpHandler(r fiber.Router, userService interfaces.UserService) {
 // @Param			id	path		int	true	"User ID"
 // @Success		200	{object}	dto.ResponseDto{data=dto.UserDto}
 // @Failure		400	{object}	dto.ResponseDto
+// @Failure		401	{object}	dto.ResponseDto
+// @Failure		403	{object}	dto.ResponseDto
 // @Failure		404	{object}	dto.ResponseDto
 // @Failure		500	{object}	dto.ResponseDto
 // @Router			/users/{id} [get]
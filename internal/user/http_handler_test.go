@@ -0,0 +1,71 @@
+package user_test
+
+import (
+	"encoding/json"
+	"go-fiber-template/internal/domain/dto"
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/user"
+	"go-fiber-template/lib/common"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/xjwt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cache"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestFindMeIsNotCachedAcrossCookieUsers(t *testing.T) {
+	config.Config.Auth.CookieMode = true
+	t.Cleanup(func() { config.Config.Auth.CookieMode = false })
+	config.Config.Jwt = config.JwtConfig{Algorithm: "HS256", SecretKey: "test", ExpiredAt: 60}
+	xjwt.Setup(config.Config.Jwt)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.Permission{}, &entity.Role{}, &entity.User{}); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: common.ErrorHandler})
+	app.Use(cache.New(config.CacheCfg))
+	user.NewHttpHandler(app.Group("/api/v1/users"), user.NewService(user.NewRepository(db), nil, nil), nil)
+
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		u := &entity.User{Name: email, Email: email, Password: "hash"}
+		if err := db.Create(u).Error; err != nil {
+			t.Fatal(err)
+		}
+		token, err := xjwt.GenerateToken(u, xjwt.TokenTypeAccess)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest(fiber.MethodGet, "/api/v1/users/me", nil)
+		req.AddCookie(&http.Cookie{Name: constant.CookieAccessToken, Value: token})
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body struct {
+			Data dto.UserDto `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Data.Email != email {
+			t.Errorf("GET /users/me as %s returned %q", email, body.Data.Email)
+		}
+		if resp.Header.Get("X-Cache") == "hit" {
+			t.Errorf("GET /users/me as %s was served from the cache", email)
+		}
+	}
+}
@@ -1,15 +1,27 @@
 package user
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"go-fiber-template/internal/domain/dto"
 	"go-fiber-template/internal/domain/entity"
 	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xjwt"
+	"go-fiber-template/lib/xkafka"
+	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
 )
 
 type service struct {
-	userRepo interfaces.UserRepository
+	userRepo       interfaces.UserRepository
+	dataExportRepo interfaces.DataExportRepository
+	kafkaClient    *xkafka.Client
 }
 
 func (s *service) FindByID(c *fiber.Ctx, id uint) (*dto.UserDto, error) {
@@ -21,18 +33,150 @@ func (s *service) FindByID(c *fiber.Ctx, id uint) (*dto.UserDto, error) {
 	return constructUserDto(user), nil
 }
 
+func (s *service) FindMe(c *fiber.Ctx) (*dto.UserDto, error) {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	return s.FindByID(c, claims.UserID())
+}
+
+func (s *service) UpdateMe(c *fiber.Ctx, req *dto.UpdateProfileRequest) (*dto.UserDto, error) {
+	claims := xjwt.ExtractTokenFromCtx(c)
+	if claims == nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+
+	user, _ := s.userRepo.FindByID(claims.UserID())
+	if user == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	user.Name = req.Name
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return constructUserDto(user), nil
+}
+
+func (s *service) Search(c *fiber.Ctx, filter *dto.UserFilter) ([]dto.AdminUserDto, error) {
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
+	if filter.Sort == "" {
+		// Newest first unless asked otherwise
+		filter.Sort = "created_at"
+		if filter.Order == "" {
+			filter.Order = "desc"
+		}
+	}
+
+	users, total, err := s.userRepo.Search(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	userDtos := make([]dto.AdminUserDto, 0, len(users))
+	for _, user := range users {
+		userDtos = append(userDtos, *constructAdminUserDto(&user))
+	}
+
+	utils.SetPaginationHeader(c, filter.Page, filter.Limit, int(total))
+	return userDtos, nil
+}
+
+func (s *service) PurgeDeleted(ctx context.Context) error {
+	gracePeriod := time.Duration(config.Config.Auth.DeletionGracePeriod) * time.Second
+	users, err := s.userRepo.FindPurgeable(time.Now().Add(-gracePeriod))
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		event, err := json.Marshal(&interfaces.UserDeletedEvent{
+			UserID:    user.ID,
+			DeletedAt: user.DeletedAt.Time,
+			PurgedAt:  time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		// The event is published first, users whose event could not be published stay unpurged
+		// and are picked up again by the next run. Consumers may see the event of a user twice.
+		if err := s.kafkaClient.Produce(ctx, "user.deleted", event); err != nil {
+			log.Error().Err(err).Uint("user_id", user.ID).Msg("Failed to publish user deleted event")
+			continue
+		}
+
+		exports, err := s.dataExportRepo.FindByUserID(user.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.userRepo.Purge(&user); err != nil {
+			return err
+		}
+
+		for _, export := range exports {
+			if export.FilePath == "" {
+				continue
+			}
+			if err := os.Remove(export.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Error().Err(err).Uint("data_export_id", export.ID).Msg("Failed to delete data export archive")
+			}
+		}
+	}
+
+	return nil
+}
+
 func constructUserDto(user *entity.User) *dto.UserDto {
 	return &dto.UserDto{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02 15:04:05"),
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.VerifiedAt != nil,
+		PendingEmail:  user.PendingEmail,
+		Roles:         user.RoleNames(),
+		CreatedAt:     user.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     user.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func constructAdminUserDto(user *entity.User) *dto.AdminUserDto {
+	adminUserDto := &dto.AdminUserDto{
+		UserDto:          *constructUserDto(user),
+		TwoFactorEnabled: user.TwoFactorEnabledAt != nil,
+		DisabledAt:       formatTime(user.DisabledAt),
+	}
+	if user.DeletedAt.Valid {
+		adminUserDto.DeletedAt = formatTime(&user.DeletedAt.Time)
+	}
+	return adminUserDto
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
 	}
+	formatted := t.Format("2006-01-02 15:04:05")
+	return &formatted
 }
 
-func NewService(userRepo interfaces.UserRepository) interfaces.UserService {
+func NewService(
+	userRepo interfaces.UserRepository,
+	dataExportRepo interfaces.DataExportRepository,
+	kafkaClient *xkafka.Client,
+) interfaces.UserService {
 	return &service{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		dataExportRepo: dataExportRepo,
+		kafkaClient:    kafkaClient,
 	}
 }
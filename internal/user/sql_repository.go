@@ -1,10 +1,19 @@
 package user
 
 import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-template/internal/domain/dto"
 	"go-fiber-template/internal/domain/entity"
 	"go-fiber-template/internal/domain/interfaces"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/database"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type repository struct {
@@ -21,7 +30,7 @@ func (r *repository) Delete(id uint) error {
 
 func (r *repository) FindByEmail(email string) (*entity.User, error) {
 	var user entity.User
-	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.db.Preload("Roles").Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 
@@ -30,15 +39,194 @@ func (r *repository) FindByEmail(email string) (*entity.User, error) {
 
 func (r *repository) FindByID(id uint) (*entity.User, error) {
 	var user entity.User
-	if err := r.db.First(&user, id).Error; err != nil {
+	if err := r.db.Preload("Roles").First(&user, id).Error; err != nil {
 		return nil, err
 	}
 
 	return &user, nil
 }
 
+// Update saves the user's own columns. Role membership is managed by the role repository.
+// Users deleted during the grace period are updated as well, logging in changes them before
+// their account is restored.
 func (r *repository) Update(user *entity.User) error {
-	return r.db.Save(user).Error
+	return r.db.Unscoped().Omit(clause.Associations).Save(user).Error
+}
+
+func (r *repository) Search(filter *dto.UserFilter) ([]entity.User, int64, error) {
+	query := r.db.Model(&entity.User{})
+	switch filter.Deleted {
+	case "include":
+		query = query.Unscoped()
+	case "only":
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if filter.Search != "" {
+		query = query.Where(clause.Or(database.Contains("name", filter.Search), database.Contains("email", filter.Search)))
+	}
+	if filter.Verified != nil {
+		query = query.Where(nullCondition("verified_at", *filter.Verified))
+	}
+	if filter.Disabled != nil {
+		query = query.Where(nullCondition("disabled_at", *filter.Disabled))
+	}
+	if from, err := time.Parse("2006-01-02", filter.CreatedFrom); err == nil {
+		query = query.Where("created_at >= ?", from)
+	}
+	if to, err := time.Parse("2006-01-02", filter.CreatedTo); err == nil {
+		// The range includes the whole last day
+		query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []entity.User
+	err := query.Preload("Roles").
+		Order(clause.OrderByColumn{Column: clause.Column{Name: filter.Sort}, Desc: filter.Order == "desc"}).
+		Order("id").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *repository) FindDeletedByEmail(email string) (*entity.User, error) {
+	var user entity.User
+	err := r.db.Unscoped().Preload("Roles").
+		Where("email = ? AND deleted_at IS NOT NULL AND purged_at IS NULL", email).
+		First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *repository) Restore(id uint) error {
+	return r.db.Unscoped().Model(&entity.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r *repository) UseTwoFactorStep(id uint, step int64) (bool, error) {
+	result := r.db.Unscoped().Model(&entity.User{}).
+		Where("id = ? AND two_factor_last_step < ?", id, step).
+		Update("two_factor_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) FindPurgeable(before time.Time) ([]entity.User, error) {
+	var users []entity.User
+	err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND purged_at IS NULL", before).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Purge keeps the row of the user, so that whatever references it stays consistent,
+// but replaces everything that identifies the person behind it. The archives of the data
+// exports of the user are left to the caller, only their rows are deleted.
+func (r *repository) Purge(user *entity.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		credentials := []any{
+			&entity.RefreshToken{},
+			&entity.Session{},
+			&entity.APIKey{},
+			&entity.RecoveryCode{},
+			&entity.PasswordResetToken{},
+			&entity.ExternalIdentity{},
+			&entity.DataExport{},
+		}
+		for _, model := range credentials {
+			if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Exec("DELETE FROM user_roles WHERE user_id = ?", user.ID).Error; err != nil {
+			return err
+		}
+
+		recipients := []string{user.Email}
+		if user.PendingEmail != "" {
+			recipients = append(recipients, user.PendingEmail)
+		}
+
+		throttleKeys := make([]string, 0, 3*len(recipients))
+		for _, recipient := range recipients {
+			recipient = strings.ToLower(recipient)
+			throttleKeys = append(throttleKeys, "account:"+recipient, "magic_link:"+recipient, "password_reset:"+recipient)
+		}
+		if err := tx.Where("throttle_key IN ?", throttleKeys).Delete(&entity.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("recipient IN ?", recipients).Delete(&entity.EmailLog{}).Error; err != nil {
+			return err
+		}
+
+		// Audit events holding the email address go, the others only refer to the anonymized row.
+		// Failed logins for the address may predate the account, those are found by their diff.
+		diffs := make([]string, 0, len(recipients))
+		for _, recipient := range recipients {
+			diff, err := json.Marshal(map[string]string{"email": recipient})
+			if err != nil {
+				return err
+			}
+			diffs = append(diffs, string(diff))
+		}
+		err := tx.Where(
+			"(target_type = ? AND target_id = ? AND action IN ?) OR (action = ? AND diff IN ?)",
+			constant.AuditTargetUser, strconv.Itoa(int(user.ID)),
+			[]string{constant.AuditActionLoginFailed, constant.AuditActionEmailChanged},
+			constant.AuditActionLoginFailed, diffs,
+		).Delete(&entity.AuditEvent{}).Error
+		if err != nil {
+			return err
+		}
+
+		// The network details of the events that stay would still tell where the person was
+		err = tx.Model(&entity.AuditEvent{}).Where(
+			"actor_id = ? OR impersonator_id = ? OR (target_type = ? AND target_id = ?)",
+			user.ID, user.ID, constant.AuditTargetUser, strconv.Itoa(int(user.ID)),
+		).Updates(map[string]any{"ip_address": "", "user_agent": ""}).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Unscoped().Model(&entity.User{}).Where("id = ?", user.ID).Updates(map[string]any{
+			"name":                  "Deleted User",
+			"email":                 fmt.Sprintf("deleted-%d@deleted.invalid", user.ID),
+			"password":              "",
+			"pending_email":         "",
+			"verified_at":           nil,
+			"two_factor_secret":     "",
+			"two_factor_enabled_at": nil,
+			"purged_at":             now,
+		}).Error
+	})
+}
+
+func nullCondition(column string, set bool) string {
+	if set {
+		return column + " IS NOT NULL"
+	}
+	return column + " IS NULL"
 }
 
 func NewRepository(db *gorm.DB) interfaces.UserRepository {
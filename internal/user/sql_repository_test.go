@@ -0,0 +1,77 @@
+package user_test
+
+import (
+	"go-fiber-template/internal/domain/entity"
+	"go-fiber-template/internal/user"
+	"go-fiber-template/lib/constant"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestPurgeBlanksTheNetworkDetailsOfAuditEvents(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(
+		&entity.Permission{},
+		&entity.Role{},
+		&entity.User{},
+		&entity.Session{},
+		&entity.RefreshToken{},
+		&entity.APIKey{},
+		&entity.RecoveryCode{},
+		&entity.PasswordResetToken{},
+		&entity.ExternalIdentity{},
+		&entity.DataExport{},
+		&entity.LoginAttempt{},
+		&entity.EmailLog{},
+		&entity.AuditEvent{},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	purged := &entity.User{Name: "Alice", Email: "alice@example.com", Password: "hash"}
+	other := &entity.User{Name: "Bob", Email: "bob@example.com", Password: "hash"}
+	for _, u := range []*entity.User{purged, other} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events := map[string]*entity.AuditEvent{
+		"acted":          {ActorID: &purged.ID, Action: constant.AuditActionLogin},
+		"impersonated":   {ActorID: &other.ID, ImpersonatorID: &purged.ID, Action: constant.AuditActionLogin},
+		"targeted":       {ActorID: &other.ID, Action: constant.AuditActionUserRoleAssigned, TargetType: constant.AuditTargetUser, TargetID: strconv.Itoa(int(purged.ID))},
+		"someone else's": {ActorID: &other.ID, Action: constant.AuditActionLogin},
+	}
+	for _, event := range events {
+		event.IPAddress = "203.0.113.7"
+		event.UserAgent = "curl/8.0"
+		if err := db.Create(event).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := user.NewRepository(db).Purge(purged); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, event := range events {
+		var stored entity.AuditEvent
+		if err := db.First(&stored, event.ID).Error; err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		blank := name != "someone else's"
+		if (stored.IPAddress == "") != blank || (stored.UserAgent == "") != blank {
+			t.Errorf("%s: ip = %q, user agent = %q, want blank %v", name, stored.IPAddress, stored.UserAgent, blank)
+		}
+		if stored.Action != event.Action {
+			t.Errorf("%s: action = %q, want %q", name, stored.Action, event.Action)
+		}
+	}
+}
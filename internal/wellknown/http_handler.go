@@ -0,0 +1,23 @@
+package wellknown
+
+import (
+	"go-fiber-template/lib/xjwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type httpHandler struct{}
+
+// NewHttpHandler registers the discovery documents served under /.well-known. They live
+// outside of /api/v1, so they are not part of the swagger documentation.
+func NewHttpHandler(r fiber.Router) {
+	handler := &httpHandler{}
+
+	r.Get("/jwks.json", handler.jwks)
+}
+
+// jwks publishes the public keys other services verify our tokens with
+func (h *httpHandler) jwks(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(xjwt.Keys().JWKS())
+}
@@ -17,16 +17,22 @@ import (
 
 func FiberCfg(cfg AppConfig) fiber.Config {
 	return fiber.Config{
-		AppName:               cfg.AppName,
-		ErrorHandler:          common.ErrorHandler,
-		DisableStartupMessage: true,
+		AppName:                 cfg.AppName,
+		ErrorHandler:            common.ErrorHandler,
+		DisableStartupMessage:   true,
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableTrustedProxyCheck: len(cfg.TrustedProxies) > 0,
+		TrustedProxies:          cfg.TrustedProxies,
+		EnableIPValidation:      true,
 	}
 }
 
+// AllowHeaders is left empty to allow the headers the preflight request asks for, since "*" does
+// not cover Authorization or X-CSRF-Token on requests with credentials.
 var CorsCfg = cors.Config{
 	AllowOrigins:     "http://localhost:3000",
 	AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH",
-	AllowHeaders:     "*",
+	AllowHeaders:     "",
 	AllowCredentials: true,
 }
 
@@ -67,14 +73,16 @@ var I18nConfig = &fiberi18n.Config{
 	DefaultLanguage: language.English,
 }
 
+// The headers are stored along with the bodies, listings carry their pagination in them. Responses
+// setting cookies are all under routes that SkipCache leaves out.
 var CacheCfg = cache.Config{
-	Next:                 nil,
+	Next:                 utils.SkipCache,
 	Expiration:           1 * time.Minute,
 	CacheHeader:          "X-Cache",
 	CacheControl:         false,
 	KeyGenerator:         utils.CacheKeyWithQueryAndHeaders,
 	ExpirationGenerator:  nil,
-	StoreResponseHeaders: false,
+	StoreResponseHeaders: true,
 	Storage:              nil,
 	MaxBytes:             0,
 	Methods:              []string{fiber.MethodGet, fiber.MethodHead},
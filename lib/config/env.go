@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/caarlos0/env/v11"
 	"github.com/go-playground/validator/v10"
 	_ "github.com/joho/godotenv/autoload"
@@ -15,20 +17,163 @@ type AppConfig struct {
 	Port      string         `env:"PORT" envDefault:"3000"`
 	GoEnv     string         `env:"GO_ENV" envDefault:"development" validate:"oneof=development production"`
 	LogFields []string       `env:"LOG_FIELDS" envSeparator:"," envDefault:"latency,status,method,url,error"`
+	BaseURL   string         `env:"BASE_URL" envDefault:"http://localhost:3000"`
 	Jwt       JwtConfig      `envPrefix:"JWT_"`
+	Auth      AuthConfig     `envPrefix:"AUTH_"`
+	Password  PasswordConfig `envPrefix:"PASSWORD_"`
+	OAuth     OAuthConfig    `envPrefix:"OAUTH_"`
+	Export    ExportConfig   `envPrefix:"EXPORT_"`
+	Audit     AuditConfig    `envPrefix:"AUDIT_"`
 	Database  DatabaseConfig `envPrefix:"DB_"`
 	Apitally  ApitallyConfig `envPrefix:"APITALLY_"`
 	Kafka     KafkaConfig    `envPrefix:"KAFKA_"`
+
+	// ProxyHeader is the header the client IP is read from behind a reverse proxy, such as X-Real-IP.
+	// Login throttling keys on the client IP, without it every client shares the IP of the proxy.
+	// Pick a header the proxy overwrites, the first entry of X-Forwarded-For is set by the client.
+	ProxyHeader string `env:"PROXY_HEADER"`
+	// TrustedProxies are the IPs or CIDR ranges of the proxies allowed to set ProxyHeader, requests
+	// from anywhere else keep their own IP.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:"," validate:"required_with=ProxyHeader"`
 }
 
 type JwtConfig struct {
-	SecretKey string `env:"SECRET_KEY" envDefault:"secret"`
-	ExpiredAt int64  `env:"EXPIRED_AT" envDefault:"3600"`
+	// Algorithm signs new tokens. HS256 uses SecretKey, RS256 and EdDSA use the private key of SigningKeyID.
+	Algorithm        string `env:"ALGORITHM" envDefault:"HS256" validate:"oneof=HS256 RS256 EdDSA"`
+	SecretKey        string `env:"SECRET_KEY" envDefault:"secret"`
+	SigningKeyID     string `env:"SIGNING_KEY_ID" validate:"required_unless=Algorithm HS256"`
+	ExpiredAt        int64  `env:"EXPIRED_AT" envDefault:"3600"`
+	RefreshExpiredAt int64  `env:"REFRESH_EXPIRED_AT" envDefault:"604800"`
+	VerifyExpiredAt  int64  `env:"VERIFY_EXPIRED_AT" envDefault:"86400"`
+	MfaExpiredAt     int64  `env:"MFA_EXPIRED_AT" envDefault:"300"`
+	// MagicLinkExpiredAt is the lifetime of the links emailed for logging in without a password.
+	MagicLinkExpiredAt int64 `env:"MAGIC_LINK_EXPIRED_AT" envDefault:"900"`
+	// ImpersonationExpiredAt is the lifetime of the tokens administrators get to act as another user.
+	ImpersonationExpiredAt int64 `env:"IMPERSONATION_EXPIRED_AT" envDefault:"900"`
+	// Keys are read from JWT_KEYS_0_ID, JWT_KEYS_0_PRIVATE_KEY_FILE and so on.
+	// Keys other than the signing one only verify tokens, which lets tokens signed
+	// before a rotation stay valid until the old key retires.
+	Keys []JwtKeyConfig `envPrefix:"KEYS_" validate:"dive"`
+}
+
+type JwtKeyConfig struct {
+	ID string `env:"ID" validate:"required"`
+	// PrivateKeyFile is a PEM encoded RSA or Ed25519 private key, PKCS#8 or PKCS#1.
+	// It can be left out for keys that only verify tokens.
+	PrivateKeyFile string `env:"PRIVATE_KEY_FILE" validate:"required_without=PublicKeyFile"`
+	PublicKeyFile  string `env:"PUBLIC_KEY_FILE"`
+	// RetiresAt is the RFC 3339 time after which the key no longer verifies tokens. Zero means never.
+	RetiresAt time.Time `env:"RETIRES_AT"`
+}
+
+type AuthConfig struct {
+	// EmailVerificationPolicy decides what unverified users may do:
+	// "optional" allows everything, "login" refuses to log them in and
+	// "routes" only blocks the routes guarded by middleware.RequireVerifiedEmail.
+	EmailVerificationPolicy string `env:"EMAIL_VERIFICATION_POLICY" envDefault:"optional" validate:"oneof=optional login routes"`
+	// PasswordResetURL is the frontend page the reset token is appended to in reset emails.
+	PasswordResetURL       string `env:"PASSWORD_RESET_URL" envDefault:"http://localhost:3000/reset-password"`
+	PasswordResetExpiredAt int64  `env:"PASSWORD_RESET_EXPIRED_AT" envDefault:"3600"`
+	// DefaultRole is assigned to every newly registered user.
+	DefaultRole string `env:"DEFAULT_ROLE" envDefault:"user"`
+	// LockoutThreshold is the number of failed logins after which an account is locked for LockoutDuration seconds.
+	// Failures older than LockoutDuration are forgotten.
+	LockoutThreshold int   `env:"LOCKOUT_THRESHOLD" envDefault:"5" validate:"min=1"`
+	LockoutDuration  int64 `env:"LOCKOUT_DURATION" envDefault:"900"`
+	// IPLockoutThreshold is the number of failed logins after which an IP address is locked for LockoutDuration
+	// seconds. It is higher than LockoutThreshold since many users can share an address.
+	IPLockoutThreshold int `env:"IP_LOCKOUT_THRESHOLD" envDefault:"20" validate:"min=1"`
+	// After every failed login, further attempts for the same account or IP address are refused for
+	// BackoffBase * 2^(failures-1) seconds, capped at BackoffMax seconds.
+	BackoffBase int64 `env:"BACKOFF_BASE" envDefault:"1"`
+	BackoffMax  int64 `env:"BACKOFF_MAX" envDefault:"300"`
+	// DeletionGracePeriod is the number of seconds a deleted account can be restored by logging in,
+	// after which its personal data is anonymized for good.
+	DeletionGracePeriod int64 `env:"DELETION_GRACE_PERIOD" envDefault:"2592000"`
+	// At most MagicLinkRateLimit login links are sent to an email address within MagicLinkRateWindow seconds.
+	MagicLinkRateLimit  int   `env:"MAGIC_LINK_RATE_LIMIT" envDefault:"3" validate:"min=1"`
+	MagicLinkRateWindow int64 `env:"MAGIC_LINK_RATE_WINDOW" envDefault:"900"`
+	// At most PasswordResetRateLimit reset emails are requested for an email address, and PasswordResetIPRateLimit
+	// from an IP address, within PasswordResetRateWindow seconds.
+	PasswordResetRateLimit   int   `env:"PASSWORD_RESET_RATE_LIMIT" envDefault:"3" validate:"min=1"`
+	PasswordResetIPRateLimit int   `env:"PASSWORD_RESET_IP_RATE_LIMIT" envDefault:"20" validate:"min=1"`
+	PasswordResetRateWindow  int64 `env:"PASSWORD_RESET_RATE_WINDOW" envDefault:"900"`
+	// MagicLinkBindDevice makes login links work only in the browser they were requested from,
+	// which is recognized by a cookie. Clients that do not keep cookies need it disabled.
+	MagicLinkBindDevice bool `env:"MAGIC_LINK_BIND_DEVICE" envDefault:"true"`
+	// CookieMode makes logins set the tokens in HttpOnly cookies instead of returning them, so that
+	// scripts in the browser cannot read them. Protected then accepts the access token cookie in place of the Authorization
+	// header, and state-changing requests made with the cookies must echo the CSRF token in the
+	// X-CSRF-Token header.
+	CookieMode bool `env:"COOKIE_MODE" envDefault:"false"`
+	// CookieSameSite is the SameSite attribute of the cookies. A frontend on another site needs None.
+	CookieSameSite string `env:"COOKIE_SAME_SITE" envDefault:"Lax" validate:"oneof=Strict Lax None"`
+	// CookieDomain shares the cookies with the subdomains of the domain. Empty means this host only.
+	CookieDomain string `env:"COOKIE_DOMAIN"`
+}
+
+type PasswordConfig struct {
+	// HashAlgorithm is used for new hashes. Hashes of the other algorithm keep verifying
+	// and are replaced the next time their owner logs in.
+	HashAlgorithm     string `env:"HASH_ALGORITHM" envDefault:"argon2id" validate:"oneof=argon2id bcrypt"`
+	BcryptCost        int    `env:"BCRYPT_COST" envDefault:"10" validate:"min=4,max=31"`
+	Argon2Memory      uint32 `env:"ARGON2_MEMORY" envDefault:"65536"`
+	Argon2Iterations  uint32 `env:"ARGON2_ITERATIONS" envDefault:"3"`
+	Argon2Parallelism uint8  `env:"ARGON2_PARALLELISM" envDefault:"2"`
+	// The rules passwords chosen by users must follow, see xpassword.PolicyConfig.
+	// With bcrypt, passwords are also limited to 72 bytes.
+	MinLength  int     `env:"MIN_LENGTH" envDefault:"8" validate:"min=1"`
+	MaxLength  int     `env:"MAX_LENGTH" envDefault:"128" validate:"gtefield=MinLength"`
+	MinClasses int     `env:"MIN_CLASSES" envDefault:"2" validate:"min=1,max=4"`
+	MinEntropy float64 `env:"MIN_ENTROPY" envDefault:"36"`
+	// BreachedListFile is checked in addition to the bundled list of common breached passwords.
+	BreachedListFile string `env:"BREACHED_LIST_FILE"`
+}
+
+type OAuthConfig struct {
+	// StateExpiredAt is the number of seconds a user has to log in at the provider.
+	StateExpiredAt int64 `env:"STATE_EXPIRED_AT" envDefault:"600"`
+	// Providers are read from OAUTH_PROVIDERS_0_NAME, OAUTH_PROVIDERS_0_CLIENT_ID and so on.
+	// Their callback URL is BASE_URL/api/v1/auth/oauth/<name>/callback.
+	Providers []OAuthProviderConfig `envPrefix:"PROVIDERS_" validate:"dive"`
+}
+
+type OAuthProviderConfig struct {
+	Name string `env:"NAME" validate:"required,alphanum,lowercase"`
+	// Type is "oidc" for OpenID Connect providers such as Google, or "github".
+	Type string `env:"TYPE" envDefault:"oidc" validate:"oneof=oidc github"`
+	// IssuerURL is where the endpoints of OpenID Connect providers are discovered from.
+	IssuerURL    string   `env:"ISSUER_URL" validate:"required_if=Type oidc"`
+	ClientID     string   `env:"CLIENT_ID" validate:"required"`
+	ClientSecret string   `env:"CLIENT_SECRET"`
+	Scopes       []string `env:"SCOPES" envSeparator:","`
+	// AuthURL, TokenURL, UserInfoURL and JWKSURL override the discovered endpoints.
+	AuthURL     string `env:"AUTH_URL"`
+	TokenURL    string `env:"TOKEN_URL"`
+	UserInfoURL string `env:"USER_INFO_URL"`
+	JWKSURL     string `env:"JWKS_URL"`
+}
+
+type ExportConfig struct {
+	// Dir is where the archives of personal data exports are stored until their download links expire.
+	Dir string `env:"DIR" envDefault:"storage/exports"`
+	// ExpiredAt is the number of seconds the download link of an export stays valid.
+	ExpiredAt int64 `env:"EXPIRED_AT" envDefault:"604800"`
+	// ClaimTimeout is the number of seconds after which an export still being assembled is given up as failed.
+	ClaimTimeout int64 `env:"CLAIM_TIMEOUT" envDefault:"900" validate:"min=1"`
+}
+
+type AuditConfig struct {
+	// Retention is the number of seconds audit events are kept for. Zero keeps them forever.
+	Retention int64 `env:"RETENTION" envDefault:"31536000" validate:"min=0"`
 }
 
 type DatabaseConfig struct {
 	Driver string `env:"DRIVER" envDefault:"postgres"`
 	Dsn    string `env:"DSN" envDefault:"host=localhost user=postgres password=secret dbname=mydb port=5432 sslmode=disable"`
+	// CursorSecret signs the cursors of listings paginated with a cursor. It must differ from
+	// JWT_SECRET_KEY, so that a leaked cursor secret cannot be used to forge tokens.
+	CursorSecret string `env:"CURSOR_SECRET" envDefault:"secret" validate:"required"`
 }
 
 type ApitallyConfig struct {
@@ -47,7 +192,6 @@ func Setup() AppConfig {
 	if err := env.Parse(&cfg); err != nil {
 		panic(err)
 	}
-
 	err := validate.Struct(cfg)
 	if err != nil {
 		panic(err)
@@ -0,0 +1,45 @@
+package constant
+
+// Actions recorded in the audit log.
+const (
+	AuditActionRegister           = "auth.register"
+	AuditActionLogin              = "auth.login"
+	AuditActionLoginFailed        = "auth.login_failed"
+	AuditActionAccountLocked      = "auth.account_locked"
+	AuditActionLogout             = "auth.logout"
+	AuditActionLogoutAll          = "auth.logout_all"
+	AuditActionEmailVerified      = "auth.email_verified"
+	AuditActionEmailChanged       = "auth.email_changed"
+	AuditActionPasswordChanged    = "auth.password_changed"
+	AuditActionPasswordReset      = "auth.password_reset"
+	AuditActionTwoFactorEnabled   = "auth.two_factor_enabled"
+	AuditActionTwoFactorDisabled  = "auth.two_factor_disabled"
+	AuditActionAccountDeleted     = "auth.account_deleted"
+	AuditActionAccountRestored    = "auth.account_restored"
+	AuditActionImpersonationStart = "auth.impersonation_started"
+	AuditActionImpersonationEnd   = "auth.impersonation_ended"
+	AuditActionUserDisabled       = "user.disabled"
+	AuditActionUserEnabled        = "user.enabled"
+	AuditActionUserUnlocked       = "user.unlocked"
+	AuditActionUserForcedLogout   = "user.forced_logout"
+	AuditActionUserRoleAssigned   = "user.role_assigned"
+	AuditActionUserRoleRemoved    = "user.role_removed"
+	AuditActionUserRolesSet       = "user.roles_set"
+	AuditActionSessionRevoked     = "session.revoked"
+	AuditActionAPIKeyCreated      = "api_key.created"
+	AuditActionAPIKeyRevoked      = "api_key.revoked"
+	AuditActionOAuthClientCreated = "oauth_client.created"
+	AuditActionOAuthClientRevoked = "oauth_client.revoked"
+	AuditActionProductCreated     = "product.created"
+	AuditActionProductUpdated     = "product.updated"
+	AuditActionProductDeleted     = "product.deleted"
+)
+
+// Types of the targets of audited actions.
+const (
+	AuditTargetUser        = "user"
+	AuditTargetProduct     = "product"
+	AuditTargetSession     = "session"
+	AuditTargetAPIKey      = "api_key"
+	AuditTargetOAuthClient = "oauth_client"
+)
@@ -0,0 +1,36 @@
+package constant
+
+const (
+	EmailVerificationOptional = "optional"
+	EmailVerificationLogin    = "login"
+	EmailVerificationRoutes   = "routes"
+)
+
+// RoleAdmin is the role holding every permission. Its users cannot be impersonated.
+const RoleAdmin = "admin"
+
+const (
+	PermissionProductWrite     = "product:write"
+	PermissionUserRead         = "user:read"
+	PermissionUserWrite        = "user:write"
+	PermissionUserImpersonate  = "user:impersonate"
+	PermissionRoleRead         = "role:read"
+	PermissionRoleWrite        = "role:write"
+	PermissionOAuthClientRead  = "oauth_client:read"
+	PermissionOAuthClientWrite = "oauth_client:write"
+	PermissionAuditRead        = "audit:read"
+)
+
+// Cookies set by logins in cookie mode. CookieCSRFToken is readable by scripts, so that they can
+// echo it in the X-CSRF-Token header.
+const (
+	CookieAccessToken  = "access_token"
+	CookieRefreshToken = "refresh_token"
+	CookieCSRFToken    = "csrf_token"
+)
+
+// GrantTypeClientCredentials is the only grant type supported by the token endpoint.
+const GrantTypeClientCredentials = "client_credentials"
+
+// ScopeTokenIntrospect must be among the scopes of an OAuth client for it to introspect tokens.
+const ScopeTokenIntrospect = "oauth:introspect"
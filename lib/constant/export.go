@@ -0,0 +1,14 @@
+package constant
+
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportReady      = "ready"
+	DataExportFailed     = "failed"
+	DataExportExpired    = "expired"
+)
+
+const (
+	DataExportFormatJSON = "json"
+	DataExportFormatZIP  = "zip"
+)
@@ -7,4 +7,6 @@ const (
 	HeaderXLimit      = "X-Limit"
 	HeaderXNextPage   = "X-Next-Page"
 	HeaderXPrevPage   = "X-Prev-Page"
+	HeaderXAPIKey     = "X-API-Key"
+	HeaderXCSRFToken  = "X-CSRF-Token"
 )
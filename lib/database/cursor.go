@@ -0,0 +1,151 @@
+package database
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned for cursors that are malformed or were not signed by us.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in a listing sorted by created_at and id, newest first. The pair is unique,
+// so unlike offsets, positions do not shift when rows are added or removed in between requests.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint      `json:"i"`
+	// Backward is set on cursors to the previous page, which is read toward newer rows.
+	Backward bool `json:"b,omitempty"`
+	// Scope is a digest of the filters and order of the listing the cursor was handed out for.
+	Scope string `json:"s,omitempty"`
+}
+
+// CursorPage holds the cursors to the pages around the one read by Paginate. A cursor is empty
+// when there is no page on that side.
+type CursorPage struct {
+	NextCursor string
+	PrevCursor string
+}
+
+// Encode signs the cursor with the secret and returns it as an opaque, URL-safe string.
+func (c Cursor) Encode(secret []byte) string {
+	payload, _ := json.Marshal(c)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signCursor(secret, encoded)
+}
+
+// DecodeCursor verifies the signature of a cursor returned by Encode and decodes it.
+func DecodeCursor(cursor string, secret []byte) (*Cursor, error) {
+	encoded, signature, found := strings.Cut(cursor, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(signCursor(secret, encoded))) {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// cursorScope digests the scope given to Paginate. The digest keeps cursors short however long the
+// filters are, and being signed along with the position, it cannot be swapped for another one.
+func cursorScope(scope string) string {
+	if scope == "" {
+		return ""
+	}
+	digest := sha256.Sum256([]byte(scope))
+	return base64.RawURLEncoding.EncodeToString(digest[:16])
+}
+
+func signCursor(secret []byte, encoded string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Paginate reads the page of at most limit rows of the query that follows the cursor, newest first.
+// An empty cursor reads the first page. The cursors are signed with secret, usually the one returned
+// by Database.CursorSecret. The query must select from a table with created_at and id columns, and
+// key must return the position of a row.
+//
+// scope describes the filters of the query. It is signed into the cursors, and a cursor handed out
+// for another scope is rejected with ErrInvalidCursor, so a cursor only resumes the listing it came from.
+//
+// Only the rows of the page are read, one more to know whether another page follows, so the cost
+// of a page does not grow with its depth the way offsets do. The rows are not counted either.
+func Paginate[T any](query *gorm.DB, secret []byte, scope string, cursor string, limit int, key func(*T) Cursor) ([]T, *CursorPage, error) {
+	scope = cursorScope(scope)
+
+	var after *Cursor
+	if cursor != "" {
+		var err error
+		if after, err = DecodeCursor(cursor, secret); err != nil {
+			return nil, nil, err
+		}
+		if after.Scope != scope {
+			return nil, nil, ErrInvalidCursor
+		}
+	}
+
+	backward := after != nil && after.Backward
+	switch {
+	case after == nil:
+	case backward:
+		query = query.Where("(created_at > ? OR (created_at = ? AND id > ?))", after.CreatedAt, after.CreatedAt, after.ID)
+	default:
+		query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))", after.CreatedAt, after.CreatedAt, after.ID)
+	}
+
+	order := "created_at DESC, id DESC"
+	if backward {
+		order = "created_at ASC, id ASC"
+	}
+
+	var rows []T
+	if err := query.Order(order).Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	// Pages read backward come in reverse
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	page := &CursorPage{}
+	if len(rows) == 0 {
+		return rows, page, nil
+	}
+
+	// Going backward, the page we came from follows. Going forward, the one we came from precedes.
+	if more || backward {
+		next := key(&rows[len(rows)-1])
+		next.Scope = scope
+		page.NextCursor = next.Encode(secret)
+	}
+	if (more && backward) || (after != nil && !backward) {
+		prev := key(&rows[0])
+		prev.Backward = true
+		prev.Scope = scope
+		page.PrevCursor = prev.Encode(secret)
+	}
+
+	return rows, page, nil
+}
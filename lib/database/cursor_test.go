@@ -0,0 +1,133 @@
+package database_test
+
+import (
+	"errors"
+	"go-fiber-template/lib/database"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var secret = []byte("secret")
+
+type item struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	Color     string
+}
+
+func itemCursor(i *item) database.Cursor {
+	return database.Cursor{CreatedAt: i.CreatedAt, ID: i.ID}
+}
+
+func newItems(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&item{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pairs of items share their creation time, so that the ID has to break ties
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 10 {
+		color := "red"
+		if i%2 == 1 {
+			color = "blue"
+		}
+		if err := db.Create(&item{CreatedAt: start.Add(time.Duration(i/2) * time.Minute), Color: color}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func ids(items []item) []uint {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func TestPaginateWalksBothWays(t *testing.T) {
+	db := newItems(t)
+
+	pages := [][]uint{{10, 9, 8, 7}, {6, 5, 4, 3}, {2, 1}}
+	var cursors []string
+	cursor := ""
+	for i, want := range pages {
+		items, page, err := database.Paginate(db.Model(&item{}), secret, "", cursor, 4, itemCursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(ids(items), want) {
+			t.Fatalf("page %d = %v, want %v", i+1, ids(items), want)
+		}
+		if (page.NextCursor == "") != (i == len(pages)-1) {
+			t.Fatalf("page %d: next cursor = %q", i+1, page.NextCursor)
+		}
+		if (page.PrevCursor == "") != (i == 0) {
+			t.Fatalf("page %d: previous cursor = %q", i+1, page.PrevCursor)
+		}
+		cursors = append(cursors, page.PrevCursor)
+		cursor = page.NextCursor
+	}
+
+	// The previous cursor of the last page leads back to the middle one
+	items, _, err := database.Paginate(db.Model(&item{}), secret, "", cursors[2], 4, itemCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids(items), pages[1]) {
+		t.Errorf("previous page = %v, want %v", ids(items), pages[1])
+	}
+}
+
+func TestPaginateRejectsForeignCursors(t *testing.T) {
+	db := newItems(t)
+
+	red := func() *gorm.DB { return db.Model(&item{}).Where("color = ?", "red") }
+	_, page, err := database.Paginate(red(), secret, "color=red", "", 2, itemCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, signature, _ := strings.Cut(page.NextCursor, ".")
+	forged := database.Cursor{CreatedAt: time.Now(), ID: 1}.Encode([]byte("other"))
+
+	tests := []struct {
+		name   string
+		scope  string
+		cursor string
+	}{
+		{"tampered position", "color=red", encoded[1:] + "." + signature},
+		{"tampered signature", "color=red", encoded + "." + signature[1:]},
+		{"unsigned", "color=red", encoded},
+		{"signed with another secret", "", forged},
+		{"other filter", "color=blue", page.NextCursor},
+		{"no filter", "", page.NextCursor},
+	}
+	for _, tt := range tests {
+		_, _, err := database.Paginate(db.Model(&item{}), secret, tt.scope, tt.cursor, 2, itemCursor)
+		if !errors.Is(err, database.ErrInvalidCursor) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, database.ErrInvalidCursor)
+		}
+	}
+
+	items, _, err := database.Paginate(red(), secret, "color=red", page.NextCursor, 2, itemCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint{5, 3}; !slices.Equal(ids(items), want) {
+		t.Errorf("next page = %v, want %v", ids(items), want)
+	}
+}
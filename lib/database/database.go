@@ -23,6 +23,9 @@ type Config struct {
 	//
 	// Default: silent
 	LogLevel string
+
+	// CursorSecret signs the cursors handed out by Paginate, so that clients cannot forge them.
+	CursorSecret string
 }
 
 // DefaultConfig provides default values for the database configuration.
@@ -114,6 +117,11 @@ func (d *Database) GetDB() *gorm.DB {
 	return d.db
 }
 
+// CursorSecret returns the key Paginate signs cursors with.
+func (d *Database) CursorSecret() []byte {
+	return []byte(d.config.CursorSecret)
+}
+
 // Ping checks if the database connection is alive.
 func (d *Database) Ping() error {
 	if d.db == nil {
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// likeEscaper escapes the wildcards of LIKE patterns with "!". A backslash is not used because
+// MySQL reads it as an escape in string literals while PostgreSQL and SQLite do not.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// Contains matches the rows whose column contains the value, ignoring case, the same way on
+// every supported driver. The column must not come from user input.
+func Contains(column, value string) clause.Expr {
+	return clause.Expr{
+		SQL:  "LOWER(" + column + ") LIKE ? ESCAPE '!'",
+		Vars: []any{"%" + likeEscaper.Replace(strings.ToLower(value)) + "%"},
+	}
+}
+
+// HasPrefix matches the rows whose column starts with the value. The column must not come from
+// user input.
+func HasPrefix(column, value string) clause.Expr {
+	return clause.Expr{
+		SQL:  column + " LIKE ? ESCAPE '!'",
+		Vars: []any{likeEscaper.Replace(value) + "%"},
+	}
+}
@@ -1,30 +1,122 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"go-fiber-template/internal/domain/dto"
 	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
 	"go-fiber-template/lib/xjwt"
+	"slices"
+	"strings"
 
 	jwtware "github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
 )
 
 // Protected protect routes
 func Protected() fiber.Handler {
 	return jwtware.New(jwtware.Config{
-		SigningKey: jwtware.SigningKey{
-			JWTAlg: jwt.SigningMethodHS256.Name,
-			Key:    []byte(config.Config.Jwt.SecretKey),
-		},
+		KeyFunc:        xjwt.Keyfunc,
 		ContextKey:     "user",
+		TokenLookup:    tokenLookup(),
+		AuthScheme:     "Bearer",
 		ErrorHandler:   jwtError,
 		SuccessHandler: jwtSuccess,
 	})
 }
 
+// tokenLookup reads the access token from the Authorization header and, in cookie mode, from the
+// access token cookie when the header is missing.
+func tokenLookup() string {
+	if config.Config.Auth.CookieMode {
+		return "header:" + fiber.HeaderAuthorization + ",cookie:" + constant.CookieAccessToken
+	}
+	return "header:" + fiber.HeaderAuthorization
+}
+
+// ProtectedWithAPIKey protects routes like Protected but also accepts an API key sent in the
+// X-API-Key header or as "Authorization: ApiKey <key>". Either way the claims are stored in the
+// same context key, so handlers do not need to know how the caller authenticated.
+func ProtectedWithAPIKey() fiber.Handler {
+	protected := Protected()
+	return func(c *fiber.Ctx) error {
+		key := extractAPIKey(c)
+		if key == "" {
+			return protected(c)
+		}
+
+		if middlewareConfig.APIKeyService == nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired API key")
+		}
+
+		claims, err := middlewareConfig.APIKeyService.Authenticate(c, key)
+		if err != nil {
+			return err
+		}
+
+		c.Locals("claims", claims)
+		return c.Next()
+	}
+}
+
+// ProtectedClient protects routes meant for other services, which authenticate with the access
+// tokens issued to OAuth clients by POST /oauth/token. Tokens of users are rejected.
+func ProtectedClient() fiber.Handler {
+	return jwtware.New(jwtware.Config{
+		KeyFunc:      xjwt.Keyfunc,
+		ContextKey:   "user",
+		ErrorHandler: jwtError,
+		SuccessHandler: func(c *fiber.Ctx) error {
+			if err := authenticateClient(c); err != nil {
+				return err
+			}
+			return c.Next()
+		},
+	})
+}
+
+// authenticateClient stores the claims of a valid client token in the context.
+func authenticateClient(c *fiber.Ctx) error {
+	jwtToken, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+	claims, err := xjwt.MapClaimsToTokenClaims(jwtToken)
+	if err != nil {
+		return err
+	}
+	if claims.Type != string(xjwt.TokenTypeClient) {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+	// Reject tokens of clients revoked after the token was issued
+	if middlewareConfig.OAuthClientService != nil {
+		if err := middlewareConfig.OAuthClientService.Validate(c, claims.ClientID); err != nil {
+			return err
+		}
+	}
+
+	c.Locals("claims", claims)
+	return nil
+}
+
+func extractAPIKey(c *fiber.Ctx) string {
+	if key := c.Get(constant.HeaderXAPIKey); key != "" {
+		return key
+	}
+
+	scheme, key, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if found && strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(key)
+	}
+
+	return ""
+}
+
 func jwtError(c *fiber.Ctx, err error) error {
-	if err.Error() == "Missing or malformed JWT" {
+	if errors.Is(err, jwtware.ErrJWTMissingOrMalformed) {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ResponseDto{
 			Message: "Missing or malformed JWT",
 		})
@@ -44,7 +136,164 @@ func jwtSuccess(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	// Refresh tokens may only be exchanged at the refresh endpoint
+	if customClaims.Type != string(xjwt.TokenTypeAccess) {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+	}
+	// Reject tokens that were revoked before they expired
+	if middlewareConfig.RevocationStore != nil {
+		revoked, err := middlewareConfig.RevocationStore.IsRevoked(customClaims.ID, customClaims.UserID(), customClaims.IssuedAt.Time)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired JWT")
+		}
+		// Logging the administrator out everywhere also ends the impersonations they started
+		if customClaims.Impersonated() {
+			revoked, err := middlewareConfig.RevocationStore.IsRevoked("", customClaims.ActorID(), customClaims.IssuedAt.Time)
+			if err != nil {
+				return err
+			}
+			if revoked {
+				return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired JWT")
+			}
+		}
+	}
+	// Reject tokens of sessions the user logged out of on another device
+	if middlewareConfig.SessionService != nil && customClaims.Family != "" {
+		if err := middlewareConfig.SessionService.Validate(c, customClaims.Family); err != nil {
+			return err
+		}
+	}
+	if customClaims.Impersonated() {
+		log.Info().
+			Uint("actor_id", customClaims.ActorID()).
+			Str("actor_email", customClaims.Actor.Email).
+			Uint("user_id", customClaims.UserID()).
+			Str("method", c.Method()).
+			Str("path", c.Path()).
+			Str("ip", c.IP()).
+			Msg("Request made while impersonating a user")
+	}
 	// Set the user in the context
 	c.Locals("claims", customClaims)
 	return c.Next()
 }
+
+// DenyImpersonation rejects requests made with an impersonation token, for actions that only the
+// user themselves may take. It must be chained after Protected.
+func DenyImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := xjwt.ExtractTokenFromCtx(c)
+		if claims != nil && claims.Impersonated() {
+			return fiber.NewError(fiber.StatusForbidden, "This action is not allowed while impersonating a user")
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireVerifiedEmail rejects users with an unverified email address when the
+// email verification policy is "routes". It must be chained after Protected.
+func RequireVerifiedEmail() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if config.Config.Auth.EmailVerificationPolicy != constant.EmailVerificationRoutes {
+			return c.Next()
+		}
+
+		claims := xjwt.ExtractTokenFromCtx(c)
+		if claims == nil || !claims.EmailVerified {
+			return fiber.NewError(fiber.StatusForbidden, "Email address is not verified")
+		}
+
+		return c.Next()
+	}
+}
+
+// RequirePermission rejects users whose roles do not grant the permission. It must be chained after Protected.
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := xjwt.ExtractTokenFromCtx(c)
+		if claims == nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+		}
+
+		if middlewareConfig.RoleService == nil {
+			return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+		}
+
+		// Scoped credentials such as API keys only get the permissions they were issued for
+		if len(claims.Scopes) > 0 && !slices.Contains(claims.Scopes, permission) {
+			return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+		}
+
+		allowed, err := middlewareConfig.RoleService.HasPermission(c, claims.Roles, permission)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireScope rejects requests whose token was not granted every one of the scopes. Requests that
+// no earlier middleware authenticated are authenticated like ProtectedClient first, so that it can
+// guard a route group on its own.
+func RequireScope(scopes ...string) fiber.Handler {
+	check := func(c *fiber.Ctx) error {
+		claims := xjwt.ExtractTokenFromCtx(c)
+		if claims == nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+		}
+
+		for _, scope := range scopes {
+			if !slices.Contains(claims.Scopes, scope) {
+				c.Set(fiber.HeaderWWWAuthenticate, fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(scopes, " ")))
+				return fiber.NewError(fiber.StatusForbidden, "Insufficient scope")
+			}
+		}
+
+		return c.Next()
+	}
+
+	protected := jwtware.New(jwtware.Config{
+		KeyFunc:      xjwt.Keyfunc,
+		ContextKey:   "user",
+		ErrorHandler: jwtError,
+		SuccessHandler: func(c *fiber.Ctx) error {
+			if err := authenticateClient(c); err != nil {
+				return err
+			}
+			return check(c)
+		},
+	})
+
+	return func(c *fiber.Ctx) error {
+		if xjwt.ExtractTokenFromCtx(c) != nil {
+			return check(c)
+		}
+		return protected(c)
+	}
+}
+
+// RequireSelfOrPermission lets users access their own resources, identified by the given route
+// parameter, and otherwise requires the permission. It must be chained after Protected.
+func RequireSelfOrPermission(param string, permission string) fiber.Handler {
+	requirePermission := RequirePermission(permission)
+	return func(c *fiber.Ctx) error {
+		claims := xjwt.ExtractTokenFromCtx(c)
+		if claims == nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid JWT token")
+		}
+
+		if id, err := c.ParamsInt(param); err == nil && uint(id) == claims.UserID() {
+			return c.Next()
+		}
+
+		return requirePermission(c)
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CSRF protects the state-changing requests authenticated by cookie in cookie mode with the double
+// submit pattern: the X-CSRF-Token header must match the CSRF token cookie. Other sites can make a
+// browser send the cookies along, but they cannot read them to fill in the header.
+func CSRF() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !config.Config.Auth.CookieMode || !authenticatedByCookie(c) {
+			return c.Next()
+		}
+
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+			return c.Next()
+		}
+
+		cookie := c.Cookies(constant.CookieCSRFToken)
+		if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(c.Get(constant.HeaderXCSRFToken))) != 1 {
+			return fiber.NewError(fiber.StatusForbidden, "Invalid CSRF token")
+		}
+
+		return c.Next()
+	}
+}
+
+// authenticatedByCookie reports whether the request carries the token cookies and no other
+// credentials, which would take precedence over them.
+func authenticatedByCookie(c *fiber.Ctx) bool {
+	if c.Cookies(constant.CookieAccessToken) == "" && c.Cookies(constant.CookieRefreshToken) == "" {
+		return false
+	}
+
+	scheme, _, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if found && strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+
+	return extractAPIKey(c) == ""
+}
@@ -0,0 +1,123 @@
+package middleware_test
+
+import (
+	"go-fiber-template/lib/config"
+	"go-fiber-template/lib/constant"
+	"go-fiber-template/lib/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCSRFChecksRequestsAuthenticatedByCookie(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.CSRF())
+	app.All("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		cookieMode bool
+		method     string
+		cookies    map[string]string
+		headers    map[string]string
+		want       int
+	}{
+		{
+			name:       "matching header",
+			cookieMode: true,
+			method:     fiber.MethodPost,
+			cookies:    map[string]string{constant.CookieAccessToken: "access", constant.CookieCSRFToken: "csrf"},
+			headers:    map[string]string{constant.HeaderXCSRFToken: "csrf"},
+			want:       fiber.StatusOK,
+		},
+		{
+			name:       "missing header",
+			cookieMode: true,
+			method:     fiber.MethodPost,
+			cookies:    map[string]string{constant.CookieAccessToken: "access", constant.CookieCSRFToken: "csrf"},
+			want:       fiber.StatusForbidden,
+		},
+		{
+			name:       "wrong header",
+			cookieMode: true,
+			method:     fiber.MethodDelete,
+			cookies:    map[string]string{constant.CookieAccessToken: "access", constant.CookieCSRFToken: "csrf"},
+			headers:    map[string]string{constant.HeaderXCSRFToken: "other"},
+			want:       fiber.StatusForbidden,
+		},
+		{
+			name:       "missing cookie",
+			cookieMode: true,
+			method:     fiber.MethodPost,
+			cookies:    map[string]string{constant.CookieAccessToken: "access"},
+			headers:    map[string]string{constant.HeaderXCSRFToken: ""},
+			want:       fiber.StatusForbidden,
+		},
+		{
+			name:       "refresh token cookie only",
+			cookieMode: true,
+			method:     fiber.MethodPost,
+			cookies:    map[string]string{constant.CookieRefreshToken: "refresh", constant.CookieCSRFToken: "csrf"},
+			want:       fiber.StatusForbidden,
+		},
+		{
+			name:       "safe method",
+			cookieMode: true,
+			method:     fiber.MethodGet,
+			cookies:    map[string]string{constant.CookieAccessToken: "access", constant.CookieCSRFToken: "csrf"},
+			want:       fiber.StatusOK,
+		},
+		{
+			name:       "bearer token",
+			cookieMode: true,
+			method:     fiber.MethodPost,
+			cookies:    map[string]string{constant.CookieAccessToken: "access"},
+			headers:    map[string]string{fiber.HeaderAuthorization: "Bearer access"},
+			want:       fiber.StatusOK,
+		},
+		{
+			name:       "API key",
+			cookieMode: true,
+			method:     fiber.MethodPost,
+			cookies:    map[string]string{constant.CookieAccessToken: "access"},
+			headers:    map[string]string{constant.HeaderXAPIKey: "key"},
+			want:       fiber.StatusOK,
+		},
+		{
+			name:       "no cookies",
+			cookieMode: true,
+			method:     fiber.MethodPost,
+			want:       fiber.StatusOK,
+		},
+		{
+			name:    "cookie mode off",
+			method:  fiber.MethodPost,
+			cookies: map[string]string{constant.CookieAccessToken: "access", constant.CookieCSRFToken: "csrf"},
+			want:    fiber.StatusOK,
+		},
+	}
+	t.Cleanup(func() { config.Config.Auth.CookieMode = false })
+	for _, tt := range tests {
+		config.Config.Auth.CookieMode = tt.cookieMode
+
+		req := httptest.NewRequest(tt.method, "/", nil)
+		for name, value := range tt.cookies {
+			req.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+		for name, value := range tt.headers {
+			req.Header.Set(name, value)
+		}
+
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, resp.StatusCode, tt.want)
+		}
+	}
+}
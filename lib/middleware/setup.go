@@ -0,0 +1,38 @@
+package middleware
+
+import "go-fiber-template/internal/domain/interfaces"
+
+// Config holds the dependencies consulted by the authentication middlewares.
+type Config struct {
+	// RevocationStore is consulted by Protected to reject revoked tokens.
+	//
+	// Default: nil, revocation is not checked
+	RevocationStore interfaces.TokenRevocationStore
+
+	// RoleService resolves the permissions of the roles carried by a token for RequirePermission.
+	//
+	// Default: nil, RequirePermission rejects every request
+	RoleService interfaces.RoleService
+
+	// APIKeyService authenticates the API keys accepted by ProtectedWithAPIKey.
+	//
+	// Default: nil, API keys are rejected
+	APIKeyService interfaces.APIKeyService
+
+	// SessionService is consulted by Protected to reject tokens of revoked sessions.
+	//
+	// Default: nil, sessions are not checked
+	SessionService interfaces.SessionService
+
+	// OAuthClientService is consulted by ProtectedClient to reject tokens of revoked clients.
+	//
+	// Default: nil, clients are not checked
+	OAuthClientService interfaces.OAuthClientService
+}
+
+var middlewareConfig Config
+
+// Setup registers the dependencies used by the middlewares. It must be called before the server starts.
+func Setup(config Config) {
+	middlewareConfig = config
+}
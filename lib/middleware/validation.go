@@ -26,3 +26,24 @@ func Validate[V any]() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// ValidateQuery is Validate for the query string.
+func ValidateQuery[V any]() fiber.Handler {
+	validate := xvalidator.XValidator
+	return func(c *fiber.Ctx) error {
+		var v V
+		if err := c.QueryParser(&v); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+
+		if validationErrors := validate.ValidateStruct(v); validationErrors != nil {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ResponseDto{
+				Message: "Validation Error",
+				Errors:  validationErrors,
+			})
+		}
+
+		c.Locals("parser", &v)
+		return c.Next()
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"go-fiber-template/lib/constant"
 	"sort"
 	"strings"
 
@@ -16,6 +17,32 @@ var cacheHeaderKeys = []string{
 	fiber.HeaderXForwardedFor,
 	fiber.HeaderXForwardedHost,
 	fiber.HeaderXForwardedProto,
+	constant.HeaderXAPIKey,
+}
+
+// uncachedPrefixes are the routes whose responses must never be replayed, as they hand out tokens,
+// cookies or redirects that are only good for a single request.
+var uncachedPrefixes = []string{
+	"/api/v1/auth",
+	"/api/v1/oauth",
+}
+
+// SkipCache reports whether the response to the request must not be cached: the request carries
+// credentials, so that the response belongs to one caller and revoked credentials must be checked,
+// or it goes to a route in uncachedPrefixes.
+func SkipCache(c *fiber.Ctx) bool {
+	if c.Get(fiber.HeaderAuthorization) != "" || c.Get(constant.HeaderXAPIKey) != "" || c.Cookies(constant.CookieAccessToken) != "" {
+		return true
+	}
+
+	path := c.Path()
+	for _, prefix := range uncachedPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
 }
 
 // CacheKeyWithQueryAndHeaders generates a cache key including both query parameters and headers
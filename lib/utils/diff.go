@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// FieldChange is the value of a field before and after a change.
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Diff compares the JSON representations of two values and returns the fields that differ, except
+// the ignored ones. Either value may be nil, for records that were created or deleted.
+func Diff(before, after any, ignore ...string) (map[string]FieldChange, error) {
+	oldFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]FieldChange)
+	for name, value := range newFields {
+		if old, ok := oldFields[name]; !ok || !reflect.DeepEqual(old, value) {
+			changes[name] = FieldChange{Old: oldFields[name], New: value}
+		}
+	}
+	for name, old := range oldFields {
+		if _, ok := newFields[name]; !ok {
+			changes[name] = FieldChange{Old: old}
+		}
+	}
+	for _, name := range ignore {
+		delete(changes, name)
+	}
+
+	return changes, nil
+}
+
+func jsonFields(v any) (map[string]any, error) {
+	fields := map[string]any{}
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"go-fiber-template/lib/constant"
+	"net/url"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -35,3 +36,25 @@ func SetPaginationHeader(ctx *fiber.Ctx, page, limit, totalCount int) {
 		ctx.Set(constant.HeaderXPrevPage, strconv.Itoa(*prevPage))
 	}
 }
+
+// SetCursorLinkHeader sets the Link header to the next and previous pages of a listing paginated
+// with a cursor (RFC 8288). The links repeat the query of the request with the cursor replaced.
+func SetCursorLinkHeader(ctx *fiber.Ctx, nextCursor, prevCursor string) {
+	query := url.Values{}
+	for key, value := range ctx.Queries() {
+		query.Set(key, value)
+	}
+
+	var links []string
+	for _, link := range []struct{ rel, cursor string }{{"next", nextCursor}, {"prev", prevCursor}} {
+		if link.cursor == "" {
+			continue
+		}
+		query.Set("cursor", link.cursor)
+		links = append(links, ctx.BaseURL()+ctx.Path()+"?"+query.Encode(), link.rel)
+	}
+
+	if len(links) > 0 {
+		ctx.Links(links...)
+	}
+}
@@ -1,16 +1,17 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import "go-fiber-template/lib/xpassword"
 
 func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hashedPassword), nil
+	return xpassword.Hash(password)
 }
 
 func CheckPasswordHash(password, hashedPassword string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	return err == nil
+	ok, err := xpassword.Verify(password, hashedPassword)
+	return err == nil && ok
+}
+
+// PasswordNeedsRehash reports whether the hash was created with an outdated algorithm or parameters.
+func PasswordNeedsRehash(hashedPassword string) bool {
+	return xpassword.NeedsRehash(hashedPassword)
 }
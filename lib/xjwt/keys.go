@@ -0,0 +1,257 @@
+package xjwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"go-fiber-template/lib/config"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Key is a key tokens are signed or verified with.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	// Private is nil for keys that only verify tokens.
+	Private any
+	Public  any
+	// RetiresAt is the time after which the key no longer verifies tokens. Zero means never.
+	RetiresAt time.Time
+}
+
+// KeySet holds the key new tokens are signed with and every key accepted when verifying tokens.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// JSONWebKey is the public part of a key as described by RFC 7517.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+var keySet *KeySet
+
+// Setup loads the signing and verification keys. It panics when a key cannot be loaded,
+// just like config.Setup does for an invalid configuration.
+func Setup(cfg config.JwtConfig) {
+	if cfg.Algorithm == jwt.SigningMethodHS256.Name && cfg.SecretKey == "secret" {
+		if config.Config.GoEnv == "production" {
+			panic("JWT_SECRET_KEY must be changed from its default value in production")
+		}
+		log.Warn().Msg("JWT_SECRET_KEY uses its default value, tokens can be forged by anyone")
+	}
+
+	ks, err := NewKeySet(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	keySet = ks
+}
+
+// NewKeySet builds the key set described by the configuration.
+func NewKeySet(cfg config.JwtConfig) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*Key)}
+
+	// Tokens signed with the shared secret carry no kid
+	if cfg.Algorithm == jwt.SigningMethodHS256.Name {
+		secret := []byte(cfg.SecretKey)
+		ks.signing = &Key{Method: jwt.SigningMethodHS256, Private: secret, Public: secret}
+		ks.keys[""] = ks.signing
+	}
+
+	for _, keyCfg := range cfg.Keys {
+		key, err := loadKey(keyCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT key %q: %w", keyCfg.ID, err)
+		}
+		if _, ok := ks.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate JWT key %q", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	if ks.signing == nil {
+		signing, ok := ks.keys[cfg.SigningKeyID]
+		if !ok {
+			return nil, fmt.Errorf("JWT signing key %q is not configured", cfg.SigningKeyID)
+		}
+		if signing.Private == nil {
+			return nil, fmt.Errorf("JWT signing key %q has no private key", cfg.SigningKeyID)
+		}
+		if signing.Method.Alg() != cfg.Algorithm {
+			return nil, fmt.Errorf("JWT signing key %q is a %s key, not %s", cfg.SigningKeyID, signing.Method.Alg(), cfg.Algorithm)
+		}
+		ks.signing = signing
+	}
+
+	return ks, nil
+}
+
+// Keyfunc looks up the key a token is verified with by its kid and refuses tokens whose
+// algorithm does not match the one of the key.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	if !key.RetiresAt.IsZero() && time.Now().After(key.RetiresAt) {
+		return nil, errors.New("signing key has been retired")
+	}
+
+	return key.Public, nil
+}
+
+// Methods returns the names of the algorithms accepted when verifying tokens.
+func (ks *KeySet) Methods() []string {
+	var methods []string
+	seen := make(map[string]bool)
+	for _, key := range ks.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWKS returns the public keys that are not retired yet. Shared secrets are never published.
+func (ks *KeySet) JWKS() JSONWebKeySet {
+	jwks := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range ks.keys {
+		if key.ID == "" || (!key.RetiresAt.IsZero() && time.Now().After(key.RetiresAt)) {
+			continue
+		}
+
+		jwk := JSONWebKey{Use: "sig", Alg: key.Method.Alg(), Kid: key.ID}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	slices.SortFunc(jwks.Keys, func(a, b JSONWebKey) int {
+		return strings.Compare(a.Kid, b.Kid)
+	})
+
+	return jwks
+}
+
+// Keys returns the key set loaded by Setup. Without Setup, tokens are signed with the shared secret.
+func Keys() *KeySet {
+	if keySet != nil {
+		return keySet
+	}
+
+	secret := []byte(config.Config.Jwt.SecretKey)
+	signing := &Key{Method: jwt.SigningMethodHS256, Private: secret, Public: secret}
+	return &KeySet{signing: signing, keys: map[string]*Key{"": signing}}
+}
+
+// Keyfunc verifies tokens against the key set loaded by Setup.
+func Keyfunc(token *jwt.Token) (any, error) {
+	return Keys().Keyfunc(token)
+}
+
+func loadKey(cfg config.JwtKeyConfig) (*Key, error) {
+	key := &Key{ID: cfg.ID, RetiresAt: cfg.RetiresAt}
+
+	if cfg.PrivateKeyFile != "" {
+		block, err := readPEM(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, errors.New("unsupported private key format")
+			}
+		}
+
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key type")
+		}
+		key.Private = private
+		key.Public = signer.Public()
+	}
+
+	if cfg.PublicKeyFile != "" {
+		block, err := readPEM(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.Public = public
+	}
+
+	switch key.Public.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM block", path)
+	}
+
+	return block, nil
+}
@@ -10,37 +10,165 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type TokenClaims struct {
 	jwt.RegisteredClaims
-	Type      string `json:"type"`
-	UserName  string `json:"user_name"`
-	UserEmail string `json:"user_email"`
+	Type          string   `json:"type"`
+	UserName      string   `json:"user_name"`
+	UserEmail     string   `json:"user_email"`
+	Family        string   `json:"family,omitempty"`
+	EmailVerified bool     `json:"email_verified"`
+	Roles         []string `json:"roles,omitempty"`
+	Scopes        []string `json:"scopes,omitempty"`
+	// Actor is set on impersonation tokens and identifies who is acting as the subject (RFC 8693).
+	Actor *Actor `json:"act,omitempty"`
+	// ClientID is set on tokens issued to OAuth clients, which act on their own behalf (RFC 9068).
+	ClientID string `json:"client_id,omitempty"`
+	// Device is set on magic link tokens and is the hash of the secret of the device the link was requested from.
+	Device string `json:"device,omitempty"`
+}
+
+// Actor is the party acting on behalf of the subject of a token.
+type Actor struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
 }
 
 type TokenType string
 
 const (
-	TokenTypeAccess  TokenType = "access"
-	TokenTypeRefresh TokenType = "refresh"
+	TokenTypeAccess            TokenType = "access"
+	TokenTypeRefresh           TokenType = "refresh"
+	TokenTypeEmailVerification TokenType = "email_verification"
+	TokenTypeAPIKey            TokenType = "api_key"
+	TokenTypeMfaPending        TokenType = "mfa_pending"
+	TokenTypeMagicLink         TokenType = "magic_link"
+	TokenTypeClient            TokenType = "client"
 )
 
-func GenerateToken(user *entity.User, tokenType TokenType) (string, error) {
+// TokenOption customizes the claims of a token before it is signed.
+type TokenOption func(*TokenClaims)
+
+// WithID sets the jti claim of the token.
+func WithID(id string) TokenOption {
+	return func(claims *TokenClaims) {
+		claims.ID = id
+	}
+}
+
+// WithFamily sets the refresh token family the token belongs to.
+func WithFamily(family string) TokenOption {
+	return func(claims *TokenClaims) {
+		claims.Family = family
+	}
+}
+
+// WithScopes restricts the permissions granted by the roles of the token to the given ones.
+// Tokens without scopes are not restricted.
+func WithScopes(scopes []string) TokenOption {
+	return func(claims *TokenClaims) {
+		claims.Scopes = scopes
+	}
+}
+
+// WithActor marks the token as issued to the actor acting as its subject.
+func WithActor(actor *entity.User) TokenOption {
+	return func(claims *TokenClaims) {
+		claims.Actor = &Actor{Subject: strconv.Itoa(int(actor.ID)), Email: actor.Email}
+	}
+}
+
+// WithDevice binds the token to the device whose secret has the given hash.
+func WithDevice(deviceHash string) TokenOption {
+	return func(claims *TokenClaims) {
+		claims.Device = deviceHash
+	}
+}
+
+// WithLifetime overrides the lifetime configured for the token type.
+func WithLifetime(lifetime time.Duration) TokenOption {
+	return func(claims *TokenClaims) {
+		claims.ExpiresAt = jwt.NewNumericDate(claims.IssuedAt.Add(lifetime))
+	}
+}
+
+// NewTokenID returns a random identifier suitable for the jti claim or a token family.
+func NewTokenID() string {
+	return uuid.NewString()
+}
+
+// TTL returns the lifetime configured for the given token type.
+func TTL(tokenType TokenType) time.Duration {
+	switch tokenType {
+	case TokenTypeRefresh:
+		return time.Duration(config.Config.Jwt.RefreshExpiredAt) * time.Second
+	case TokenTypeEmailVerification:
+		return time.Duration(config.Config.Jwt.VerifyExpiredAt) * time.Second
+	case TokenTypeMfaPending:
+		return time.Duration(config.Config.Jwt.MfaExpiredAt) * time.Second
+	case TokenTypeMagicLink:
+		return time.Duration(config.Config.Jwt.MagicLinkExpiredAt) * time.Second
+	default:
+		return time.Duration(config.Config.Jwt.ExpiredAt) * time.Second
+	}
+}
+
+// NewClaims builds the claims describing the user for a token of the given type.
+func NewClaims(user *entity.User, tokenType TokenType, opts ...TokenOption) *TokenClaims {
 	claims := &TokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   strconv.Itoa(int(user.ID)),
 			Issuer:    config.Config.AppName,
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(config.Config.Jwt.ExpiredAt) * time.Second)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TTL(tokenType))),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        NewTokenID(),
+		},
+		Type:          string(tokenType),
+		UserName:      user.Name,
+		UserEmail:     user.Email,
+		EmailVerified: user.VerifiedAt != nil,
+		Roles:         user.RoleNames(),
+	}
+
+	for _, opt := range opts {
+		opt(claims)
+	}
+
+	return claims
+}
+
+// NewClientClaims builds the claims of an access token issued to an OAuth client. The subject
+// of the token is the client itself, so it carries no user.
+func NewClientClaims(clientID string, scopes []string) *TokenClaims {
+	return &TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   clientID,
+			Issuer:    config.Config.AppName,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TTL(TokenTypeClient))),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        NewTokenID(),
 		},
-		Type:      string(tokenType),
-		UserName:  user.Name,
-		UserEmail: user.Email,
+		Type:     string(TokenTypeClient),
+		ClientID: clientID,
+		Scopes:   scopes,
+	}
+}
+
+func GenerateToken(user *entity.User, tokenType TokenType, opts ...TokenOption) (string, error) {
+	return SignClaims(NewClaims(user, tokenType, opts...))
+}
+
+// SignClaims signs the claims with the current signing key.
+func SignClaims(claims *TokenClaims) (string, error) {
+	signing := Keys().signing
+	token := jwt.NewWithClaims(signing.Method, claims)
+	if signing.ID != "" {
+		token.Header["kid"] = signing.ID
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(config.Config.Jwt.SecretKey))
+	tokenString, err := token.SignedString(signing.Private)
 	if err != nil {
 		return "", err
 	}
@@ -48,6 +176,54 @@ func GenerateToken(user *entity.User, tokenType TokenType) (string, error) {
 	return tokenString, nil
 }
 
+// ParseToken verifies the signature and expiry of a token and checks that it is of the expected type.
+func ParseToken(tokenString string, tokenType TokenType) (*TokenClaims, error) {
+	claims, err := VerifyToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Type != string(tokenType) {
+		return nil, errors.New("invalid token type")
+	}
+
+	return claims, nil
+}
+
+// VerifyToken verifies the signature and expiry of a token of any type. Callers must check the
+// type of the token themselves before trusting it for anything.
+func VerifyToken(tokenString string) (*TokenClaims, error) {
+	keys := Keys()
+
+	var claims TokenClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, keys.Keyfunc, jwt.WithValidMethods(keys.Methods()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+// UserID returns the ID of the user the token was issued to.
+func (c *TokenClaims) UserID() uint {
+	id, _ := strconv.ParseUint(c.Subject, 10, 64)
+	return uint(id)
+}
+
+// Impersonated reports whether the token was issued to someone acting as its subject.
+func (c *TokenClaims) Impersonated() bool {
+	return c.Actor != nil
+}
+
+// ActorID returns the ID of the user acting as the subject, or zero when the token is not impersonated.
+func (c *TokenClaims) ActorID() uint {
+	if c.Actor == nil {
+		return 0
+	}
+	id, _ := strconv.ParseUint(c.Actor.Subject, 10, 64)
+	return uint(id)
+}
+
 func MapClaimsToTokenClaims(token *jwt.Token) (*TokenClaims, error) {
 	mapClaims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
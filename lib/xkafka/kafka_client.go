@@ -105,6 +105,16 @@ func NewClient(config ...Config) (*Client, error) {
 	return client, nil
 }
 
+// NewClientWithProducer creates a Kafka client that sends messages with the given producer,
+// such as the mock of sarama/mocks in tests.
+func NewClientWithProducer(producer sarama.SyncProducer, config ...Config) *Client {
+	return &Client{
+		config:   setConfig(config...),
+		producer: producer,
+		closed:   make(chan struct{}),
+	}
+}
+
 // Produce sends a message to the specified topic.
 func (c *Client) Produce(ctx context.Context, topic string, value []byte) error {
 	msg := &sarama.ProducerMessage{
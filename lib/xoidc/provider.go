@@ -0,0 +1,422 @@
+package xoidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TypeOIDC is a provider implementing OpenID Connect, whose endpoints are discovered from its issuer.
+	TypeOIDC = "oidc"
+	// TypeGitHub is GitHub, which only implements OAuth 2.0 and exposes the user through its REST API.
+	TypeGitHub = "github"
+)
+
+// Config holds the configuration of a provider users can log in with.
+type Config struct {
+	// Name identifies the provider in URLs and in the identities linked to users.
+	Name string
+
+	// Type is either TypeOIDC or TypeGitHub.
+	//
+	// Default: TypeOIDC
+	Type string
+
+	// IssuerURL is the issuer of the ID tokens, the discovery document is read from
+	// IssuerURL/.well-known/openid-configuration. Required for TypeOIDC.
+	IssuerURL string
+
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL is the callback URL registered at the provider.
+	RedirectURL string
+
+	// Scopes are requested in addition to the ones the type needs.
+	//
+	// Default: "openid email profile" for TypeOIDC, "read:user user:email" for TypeGitHub
+	Scopes []string
+
+	// AuthURL, TokenURL, UserInfoURL and JWKSURL override the discovered endpoints,
+	// or the well-known ones of GitHub.
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	JWKSURL     string
+
+	// HTTPClient sends the requests to the provider.
+	//
+	// Default: a client with a 10 second timeout
+	HTTPClient *http.Client
+}
+
+// Token is the response of the token endpoint.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+// Identity is the user as described by the provider.
+type Identity struct {
+	// Subject is the stable identifier of the user at the provider.
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider runs the authorization code flow with PKCE against a single provider.
+type Provider struct {
+	config Config
+
+	mu         sync.Mutex
+	discovered bool
+	keys       map[string]any
+}
+
+// Providers holds the configured providers by name.
+type Providers map[string]*Provider
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// New creates a provider. The endpoints of OpenID Connect providers are discovered on first use,
+// so that an unreachable provider does not keep the application from starting.
+func New(config Config) (*Provider, error) {
+	cfg := setConfig(config)
+
+	if cfg.Name == "" {
+		return nil, errors.New("provider name is required")
+	}
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("client ID of provider %q is required", cfg.Name)
+	}
+	switch cfg.Type {
+	case TypeOIDC:
+		if cfg.IssuerURL == "" {
+			return nil, fmt.Errorf("issuer URL of provider %q is required", cfg.Name)
+		}
+	case TypeGitHub:
+	default:
+		return nil, fmt.Errorf("provider %q has unsupported type %q", cfg.Name, cfg.Type)
+	}
+
+	return &Provider{config: cfg}, nil
+}
+
+// NewProviders creates a provider for every configuration.
+func NewProviders(configs ...Config) (Providers, error) {
+	providers := make(Providers, len(configs))
+	for _, config := range configs {
+		provider, err := New(config)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := providers[provider.Name()]; ok {
+			return nil, fmt.Errorf("duplicate provider %q", provider.Name())
+		}
+		providers[provider.Name()] = provider
+	}
+	return providers, nil
+}
+
+// setConfig fills in the default values of the configuration.
+func setConfig(config Config) Config {
+	cfg := config
+
+	if cfg.Type == "" {
+		cfg.Type = TypeOIDC
+	}
+	cfg.IssuerURL = strings.TrimSuffix(cfg.IssuerURL, "/")
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if cfg.Type == TypeGitHub {
+		if len(cfg.Scopes) == 0 {
+			cfg.Scopes = []string{"read:user", "user:email"}
+		}
+		if cfg.AuthURL == "" {
+			cfg.AuthURL = "https://github.com/login/oauth/authorize"
+		}
+		if cfg.TokenURL == "" {
+			cfg.TokenURL = "https://github.com/login/oauth/access_token"
+		}
+		if cfg.UserInfoURL == "" {
+			cfg.UserInfoURL = "https://api.github.com/user"
+		}
+	} else {
+		scopes := []string{"openid", "email", "profile"}
+		for _, scope := range cfg.Scopes {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+		cfg.Scopes = scopes
+	}
+
+	return cfg
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// AuthCodeURL returns the URL of the provider the user is redirected to. The state and nonce
+// must be checked on the callback, the verifier is sent along with the authorization code.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	if p.config.Type == TypeOIDC {
+		params.Set("nonce", nonce)
+	}
+
+	separator := "?"
+	if strings.Contains(p.config.AuthURL, "?") {
+		separator = "&"
+	}
+	return p.config.AuthURL + separator + params.Encode(), nil
+}
+
+// Exchange trades the authorization code for the tokens of the user.
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (*Token, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"client_secret": {p.config.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var body struct {
+		Token
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	// GitHub answers errors of the token endpoint with 200 OK, so the body is checked as well
+	if err := p.do(req, &body, http.StatusBadRequest, http.StatusUnauthorized); err != nil {
+		return nil, err
+	}
+	if body.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned %s: %s", body.Error, body.ErrorDescription)
+	}
+	if body.AccessToken == "" {
+		return nil, errors.New("token endpoint returned no access token")
+	}
+	if p.config.Type == TypeOIDC && body.IDToken == "" {
+		return nil, errors.New("token endpoint returned no ID token")
+	}
+
+	return &body.Token, nil
+}
+
+// Identity returns the user the token was issued for. For OpenID Connect providers the ID token
+// is verified and must carry the nonce sent with the authorization request.
+func (p *Provider) Identity(ctx context.Context, token *Token, nonce string) (*Identity, error) {
+	if p.config.Type == TypeGitHub {
+		return p.githubIdentity(ctx, token)
+	}
+
+	identity, err := p.verifyIDToken(ctx, token.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// Some providers leave the email out of the ID token and only return it from the userinfo endpoint
+	if identity.Email == "" && p.config.UserInfoURL != "" {
+		var userInfo struct {
+			Subject       string `json:"sub"`
+			Email         string `json:"email"`
+			EmailVerified any    `json:"email_verified"`
+			Name          string `json:"name"`
+		}
+		if err := p.get(ctx, p.config.UserInfoURL, token, &userInfo); err != nil {
+			return nil, err
+		}
+		if userInfo.Subject != identity.Subject {
+			return nil, errors.New("userinfo subject does not match the ID token")
+		}
+		identity.Email = userInfo.Email
+		identity.EmailVerified = isTrue(userInfo.EmailVerified)
+		if identity.Name == "" {
+			identity.Name = userInfo.Name
+		}
+	}
+
+	return identity, nil
+}
+
+// githubIdentity reads the user and their primary email from the GitHub API.
+func (p *Provider) githubIdentity(ctx context.Context, token *Token) (*Identity, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := p.get(ctx, p.config.UserInfoURL, token, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errors.New("GitHub returned no user ID")
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.get(ctx, p.config.UserInfoURL+"/emails", token, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Subject: fmt.Sprintf("%d", user.ID),
+		Name:    user.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+
+	return identity, nil
+}
+
+// discover reads the endpoints that are not configured from the discovery document of the issuer.
+func (p *Provider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovered || p.config.Type != TypeOIDC {
+		return nil
+	}
+	if p.config.AuthURL != "" && p.config.TokenURL != "" && p.config.JWKSURL != "" {
+		p.discovered = true
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.IssuerURL+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return err
+	}
+
+	var document discoveryDocument
+	if err := p.do(req, &document); err != nil {
+		return fmt.Errorf("failed to discover provider %q: %w", p.config.Name, err)
+	}
+	if strings.TrimSuffix(document.Issuer, "/") != p.config.IssuerURL {
+		return fmt.Errorf("provider %q announced issuer %q instead of %q", p.config.Name, document.Issuer, p.config.IssuerURL)
+	}
+
+	if p.config.AuthURL == "" {
+		p.config.AuthURL = document.AuthorizationEndpoint
+	}
+	if p.config.TokenURL == "" {
+		p.config.TokenURL = document.TokenEndpoint
+	}
+	if p.config.UserInfoURL == "" {
+		p.config.UserInfoURL = document.UserInfoEndpoint
+	}
+	if p.config.JWKSURL == "" {
+		p.config.JWKSURL = document.JWKSURI
+	}
+	if p.config.AuthURL == "" || p.config.TokenURL == "" || p.config.JWKSURL == "" {
+		return fmt.Errorf("discovery document of provider %q is missing endpoints", p.config.Name)
+	}
+
+	p.discovered = true
+	return nil
+}
+
+// get calls an API of the provider on behalf of the user.
+func (p *Provider) get(ctx context.Context, endpoint string, token *Token, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	return p.do(req, v)
+}
+
+// do sends the request and decodes the JSON response. Responses with a status other than
+// 200 OK or one of the accepted ones are errors.
+func (p *Provider) do(req *http.Request, v any, accepted ...int) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK && !slices.Contains(accepted, resp.StatusCode) {
+		return fmt.Errorf("%s %s returned status %d", req.Method, req.URL.Redacted(), resp.StatusCode)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// CodeChallenge derives the S256 PKCE challenge of a verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// isTrue reads a boolean claim, which some providers send as a string.
+func isTrue(v any) bool {
+	switch value := v.(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
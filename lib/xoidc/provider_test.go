@@ -0,0 +1,158 @@
+package xoidc_test
+
+import (
+	"context"
+	"go-fiber-template/lib/xoidc"
+	"go-fiber-template/lib/xoidc/xoidctest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const redirectURL = "http://localhost:3000/api/v1/auth/oauth/fake/callback"
+
+func newProvider(t *testing.T) (*xoidctest.Server, *xoidc.Provider) {
+	t.Helper()
+
+	server, err := xoidctest.NewServer("client", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	provider, err := xoidc.New(server.Config("fake", redirectURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return server, provider
+}
+
+// login runs the authorization code flow and returns the identity of the logged in user.
+func login(t *testing.T, server *xoidctest.Server, provider *xoidc.Provider, user xoidctest.Login) (*xoidc.Identity, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback, err := server.Authorize(authURL, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(callback)
+
+	token, err := provider.Exchange(ctx, u.Query().Get("code"), "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return provider.Identity(ctx, token, "nonce")
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// Example of RFC 7636, appendix B
+	challenge := xoidc.CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if challenge != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Errorf("CodeChallenge() = %q", challenge)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	server, provider := newProvider(t)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authURL, server.URL+"/authorize?") {
+		t.Fatalf("AuthCodeURL() = %q, want the discovered endpoint", authURL)
+	}
+
+	u, _ := url.Parse(authURL)
+	params := u.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client",
+		"redirect_uri":          redirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "state",
+		"nonce":                 "nonce",
+		"code_challenge":        xoidc.CodeChallenge("verifier"),
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if params.Get(name) != value {
+			t.Errorf("%s = %q, want %q", name, params.Get(name), value)
+		}
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	server, provider := newProvider(t)
+
+	identity, err := login(t, server, provider, xoidctest.Login{
+		Subject:       "subject",
+		Email:         "user@example.com",
+		EmailVerified: true,
+		Name:          "User",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := xoidc.Identity{Subject: "subject", Email: "user@example.com", EmailVerified: true, Name: "User"}
+	if *identity != want {
+		t.Errorf("Identity() = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestExchangeRequiresVerifier(t *testing.T) {
+	server, provider := newProvider(t)
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback, err := server.Authorize(authURL, xoidctest.Login{Subject: "subject"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(callback)
+	code := u.Query().Get("code")
+
+	if _, err := provider.Exchange(ctx, code, "other verifier"); err == nil {
+		t.Fatal("Exchange() with the wrong verifier succeeded")
+	}
+	// The code is used up by the failed attempt
+	if _, err := provider.Exchange(ctx, code, "verifier"); err == nil {
+		t.Fatal("Exchange() of a used code succeeded")
+	}
+}
+
+func TestIdentityRejectsInvalidIDTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		login xoidctest.Login
+	}{
+		{"nonce mismatch", xoidctest.Login{Claims: map[string]any{"nonce": "other"}}},
+		{"missing nonce", xoidctest.Login{Claims: map[string]any{"nonce": ""}}},
+		{"forged signature", xoidctest.Login{Forged: true}},
+		{"wrong audience", xoidctest.Login{Claims: map[string]any{"aud": "other client"}}},
+		{"wrong issuer", xoidctest.Login{Claims: map[string]any{"iss": "https://issuer.example.com"}}},
+		{"expired", xoidctest.Login{Claims: map[string]any{"exp": 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, provider := newProvider(t)
+
+			tt.login.Subject = "subject"
+			if identity, err := login(t, server, provider, tt.login); err == nil {
+				t.Fatalf("Identity() = %+v, want an error", identity)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Package xoidctest provides a fake OpenID Connect provider for tests.
+package xoidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"go-fiber-template/lib/utils"
+	"go-fiber-template/lib/xoidc"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keyID identifies the key the server publishes in its JWKS.
+const keyID = "xoidctest"
+
+// Login describes the user logging in at the provider and the ID token issued to them.
+type Login struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+
+	// Claims are added to the ID token and override the ones the server sets, such as "aud" or "nonce".
+	Claims jwt.MapClaims
+
+	// Forged signs the ID token with a key that is not in the JWKS, under the same key ID.
+	Forged bool
+}
+
+// grant is an authorization code waiting to be exchanged.
+type grant struct {
+	login       Login
+	clientID    string
+	redirectURI string
+	challenge   string
+	nonce       string
+}
+
+// Server is a provider serving discovery, JWKS and token endpoints. Authorization is not served
+// over HTTP, tests call Authorize instead of following the redirect to the provider.
+type Server struct {
+	*httptest.Server
+
+	ClientID     string
+	ClientSecret string
+
+	key    *rsa.PrivateKey
+	forger *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]*grant
+}
+
+// NewServer starts a provider that accepts the given client. Close it when the test is done.
+func NewServer(clientID, clientSecret string) (*Server, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	forger, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		forger:       forger,
+		grants:       make(map[string]*grant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("POST /token", s.token)
+	s.Server = httptest.NewServer(mux)
+
+	return s, nil
+}
+
+// Config returns the configuration of a provider with the given name that logs in at the server.
+func (s *Server) Config(name, redirectURL string) xoidc.Config {
+	return xoidc.Config{
+		Name:         name,
+		IssuerURL:    s.URL,
+		ClientID:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+		RedirectURL:  redirectURL,
+	}
+}
+
+// Authorize logs the user in at the authorization URL returned by xoidc.Provider.AuthCodeURL,
+// and returns the URL the provider redirects the user back to, with the code and state.
+func (s *Server) Authorize(authURL string, login Login) (string, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", err
+	}
+	params := u.Query()
+
+	code, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.grants[code] = &grant{
+		login:       login,
+		clientID:    params.Get("client_id"),
+		redirectURI: params.Get("redirect_uri"),
+		challenge:   params.Get("code_challenge"),
+		nonce:       params.Get("nonce"),
+	}
+	s.mu.Unlock()
+
+	callback := url.Values{
+		"code":  {code},
+		"state": {params.Get("state")},
+	}
+	return params.Get("redirect_uri") + "?" + callback.Encode(), nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	})
+}
+
+// token exchanges a code once, for the client and redirect URI it was issued to, and only with
+// the verifier matching the PKCE challenge of the authorization request.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	s.mu.Lock()
+	g, ok := s.grants[r.PostForm.Get("code")]
+	delete(s.grants, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	switch {
+	case r.PostForm.Get("client_id") != s.ClientID || r.PostForm.Get("client_secret") != s.ClientSecret:
+		tokenError(w, "invalid_client")
+		return
+	case !ok || r.PostForm.Get("grant_type") != "authorization_code":
+		tokenError(w, "invalid_grant")
+		return
+	case g.clientID != s.ClientID || g.redirectURI != r.PostForm.Get("redirect_uri"):
+		tokenError(w, "invalid_grant")
+		return
+	case g.challenge == "" || xoidc.CodeChallenge(r.PostForm.Get("code_verifier")) != g.challenge:
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	idToken, err := s.idToken(g)
+	if err != nil {
+		tokenError(w, "server_error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "access-" + g.login.Subject,
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+func (s *Server) idToken(g *grant) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.URL,
+		"aud":            s.ClientID,
+		"sub":            g.login.Subject,
+		"email":          g.login.Email,
+		"email_verified": g.login.EmailVerified,
+		"name":           g.login.Name,
+		"nonce":          g.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+	}
+	for name, value := range g.login.Claims {
+		claims[name] = value
+	}
+
+	key := s.key
+	if g.login.Forged {
+		key = s.forger
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(key)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
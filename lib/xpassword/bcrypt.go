@@ -0,0 +1,47 @@
+package xpassword
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptMaxBytes is the length past which bcrypt refuses passwords.
+const BcryptMaxBytes = 72
+
+// bcryptAlgorithm keeps verifying the hashes created before Argon2id was introduced.
+// bcrypt only looks at the first 72 bytes of a password, so longer ones are refused
+// instead of being silently truncated. The policy keeps users from choosing them.
+type bcryptAlgorithm struct {
+	cost int
+}
+
+func (a *bcryptAlgorithm) Name() string {
+	return AlgorithmBcrypt
+}
+
+func (a *bcryptAlgorithm) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), a.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (a *bcryptAlgorithm) Matches(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func (a *bcryptAlgorithm) Verify(password, hash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (a *bcryptAlgorithm) Outdated(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != a.cost
+}
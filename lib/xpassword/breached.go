@@ -0,0 +1,72 @@
+package xpassword
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+)
+
+// breachedPasswords holds the SHA-1 hashes of the most common passwords found in breaches.
+//
+//go:embed breached_passwords.txt
+var breachedPasswords string
+
+// BreachedList is an offline list of the SHA-1 hashes of breached passwords. Like the Have I Been
+// Pwned range API, hashes are grouped by their first five hex characters, so that a lookup only
+// touches the suffixes sharing the prefix of the password.
+type BreachedList struct {
+	ranges map[string]map[string]struct{}
+}
+
+// NewBreachedList returns the bundled list of common breached passwords.
+func NewBreachedList() *BreachedList {
+	list := &BreachedList{ranges: make(map[string]map[string]struct{})}
+	_ = list.Load(strings.NewReader(breachedPasswords))
+	return list
+}
+
+// LoadFile adds the hashes of a file in the Have I Been Pwned format to the list.
+func (l *BreachedList) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return l.Load(file)
+}
+
+// Load adds hashes read one per line to the list. Anything after a colon, such as the
+// number of occurrences in the Have I Been Pwned format, is ignored.
+func (l *BreachedList) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if len(hash) != sha1.Size*2 {
+			continue
+		}
+		hash = strings.ToUpper(hash)
+
+		suffixes, ok := l.ranges[hash[:5]]
+		if !ok {
+			suffixes = make(map[string]struct{})
+			l.ranges[hash[:5]] = suffixes
+		}
+		suffixes[hash[5:]] = struct{}{}
+	}
+
+	return scanner.Err()
+}
+
+// Contains reports whether the password appears in the list.
+func (l *BreachedList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	_, ok := l.ranges[hash[:5]][hash[5:]]
+	return ok
+}
@@ -0,0 +1,87 @@
+package xpassword
+
+import "golang.org/x/crypto/bcrypt"
+
+// Config holds the policy new password hashes are created with.
+type Config struct {
+	// Algorithm is the algorithm new hashes are created with.
+	// Possible values: "argon2id", "bcrypt".
+	//
+	// Default: argon2id
+	Algorithm string
+
+	// BcryptCost is the cost of new bcrypt hashes.
+	//
+	// Default: bcrypt.DefaultCost
+	BcryptCost int
+
+	// Argon2Memory is the memory used by Argon2id, in KiB.
+	//
+	// Default: 65536
+	Argon2Memory uint32
+
+	// Argon2Iterations is the number of passes over the memory.
+	//
+	// Default: 3
+	Argon2Iterations uint32
+
+	// Argon2Parallelism is the number of threads used by Argon2id.
+	//
+	// Default: 2
+	Argon2Parallelism uint8
+
+	// Argon2SaltLength is the length of the random salt, in bytes.
+	//
+	// Default: 16
+	Argon2SaltLength uint32
+
+	// Argon2KeyLength is the length of the derived key, in bytes.
+	//
+	// Default: 32
+	Argon2KeyLength uint32
+}
+
+// DefaultConfig follows the OWASP recommendations for Argon2id.
+var DefaultConfig = Config{
+	Algorithm:         AlgorithmArgon2id,
+	BcryptCost:        bcrypt.DefaultCost,
+	Argon2Memory:      64 * 1024,
+	Argon2Iterations:  3,
+	Argon2Parallelism: 2,
+	Argon2SaltLength:  16,
+	Argon2KeyLength:   32,
+}
+
+// setConfig sets the configuration for the hasher.
+func setConfig(config ...Config) Config {
+	if len(config) == 0 {
+		return DefaultConfig
+	}
+
+	// Override default config with provided configs
+	cfg := config[0]
+
+	// Set default values if not provided
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = DefaultConfig.Algorithm
+	}
+	if cfg.BcryptCost == 0 {
+		cfg.BcryptCost = DefaultConfig.BcryptCost
+	}
+	if cfg.Argon2Memory == 0 {
+		cfg.Argon2Memory = DefaultConfig.Argon2Memory
+	}
+	if cfg.Argon2Iterations == 0 {
+		cfg.Argon2Iterations = DefaultConfig.Argon2Iterations
+	}
+	if cfg.Argon2Parallelism == 0 {
+		cfg.Argon2Parallelism = DefaultConfig.Argon2Parallelism
+	}
+	if cfg.Argon2SaltLength == 0 {
+		cfg.Argon2SaltLength = DefaultConfig.Argon2SaltLength
+	}
+	if cfg.Argon2KeyLength == 0 {
+		cfg.Argon2KeyLength = DefaultConfig.Argon2KeyLength
+	}
+	return cfg
+}
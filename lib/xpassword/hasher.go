@@ -0,0 +1,114 @@
+package xpassword
+
+import "errors"
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// ErrUnknownFormat is returned when a stored hash was not created by any supported algorithm.
+var ErrUnknownFormat = errors.New("unknown password hash format")
+
+// Algorithm is a password hashing algorithm. Every algorithm encodes its parameters
+// in the hashes it creates, so that a hash can be verified after the policy changed.
+type Algorithm interface {
+	// Name returns the name the algorithm is configured with.
+	Name() string
+	// Hash hashes the password with the parameters of the algorithm.
+	Hash(password string) (string, error)
+	// Matches reports whether the hash was created by this algorithm.
+	Matches(hash string) bool
+	// Verify reports whether the password matches the hash.
+	Verify(password, hash string) (bool, error)
+	// Outdated reports whether the hash was created with other parameters than the current ones.
+	Outdated(hash string) bool
+}
+
+// Hasher creates hashes with the configured algorithm and verifies hashes of every supported algorithm.
+type Hasher struct {
+	current    Algorithm
+	algorithms []Algorithm
+}
+
+func New(config ...Config) *Hasher {
+	cfg := setConfig(config...)
+
+	algorithms := []Algorithm{
+		&argon2idAlgorithm{
+			memory:      cfg.Argon2Memory,
+			iterations:  cfg.Argon2Iterations,
+			parallelism: cfg.Argon2Parallelism,
+			saltLength:  cfg.Argon2SaltLength,
+			keyLength:   cfg.Argon2KeyLength,
+		},
+		&bcryptAlgorithm{cost: cfg.BcryptCost},
+	}
+
+	hasher := &Hasher{algorithms: algorithms}
+	for _, algorithm := range algorithms {
+		if algorithm.Name() == cfg.Algorithm {
+			hasher.current = algorithm
+		}
+	}
+	if hasher.current == nil {
+		panic("unsupported password hash algorithm: " + cfg.Algorithm)
+	}
+
+	return hasher
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify checks the password against a hash created by any supported algorithm.
+func (h *Hasher) Verify(password, hash string) (bool, error) {
+	algorithm := h.algorithmOf(hash)
+	if algorithm == nil {
+		return false, ErrUnknownFormat
+	}
+
+	return algorithm.Verify(password, hash)
+}
+
+// NeedsRehash reports whether the hash should be replaced by one created with the current policy.
+func (h *Hasher) NeedsRehash(hash string) bool {
+	algorithm := h.algorithmOf(hash)
+	if algorithm == nil || algorithm != h.current {
+		return true
+	}
+
+	return algorithm.Outdated(hash)
+}
+
+func (h *Hasher) algorithmOf(hash string) Algorithm {
+	for _, algorithm := range h.algorithms {
+		if algorithm.Matches(hash) {
+			return algorithm
+		}
+	}
+	return nil
+}
+
+var hasher = New()
+
+// Setup replaces the default hasher with one following the configured policy.
+func Setup(config ...Config) {
+	hasher = New(config...)
+}
+
+// Hash hashes the password with the hasher set up by Setup.
+func Hash(password string) (string, error) {
+	return hasher.Hash(password)
+}
+
+// Verify verifies the password with the hasher set up by Setup.
+func Verify(password, hash string) (bool, error) {
+	return hasher.Verify(password, hash)
+}
+
+// NeedsRehash checks the hash against the policy of the hasher set up by Setup.
+func NeedsRehash(hash string) bool {
+	return hasher.NeedsRehash(hash)
+}
@@ -0,0 +1,260 @@
+package xpassword
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// PolicyConfig holds the rules passwords chosen by users must follow.
+type PolicyConfig struct {
+	// MinLength is the minimum number of characters.
+	//
+	// Default: 8
+	MinLength int
+
+	// MaxLength is the maximum number of characters.
+	//
+	// Default: 128
+	MaxLength int
+
+	// MaxBytes is the maximum length in bytes, for hash algorithms that ignore the rest
+	// of longer passwords. Set it to BcryptMaxBytes when new hashes are bcrypt ones.
+	//
+	// Default: 0, no limit
+	MaxBytes int
+
+	// MinClasses is the number of character classes, out of lowercase letters,
+	// uppercase letters, digits and symbols, a password must mix.
+	//
+	// Default: 2
+	MinClasses int
+
+	// MinEntropy is the minimum estimated strength of a password, in bits.
+	//
+	// Default: 36
+	MinEntropy float64
+
+	// BreachedListFile is a file of SHA-1 hashes in the Have I Been Pwned format, checked
+	// in addition to the bundled list of common breached passwords.
+	//
+	// Default: ""
+	BreachedListFile string
+}
+
+// DefaultPolicyConfig provides default values for the password policy.
+var DefaultPolicyConfig = PolicyConfig{
+	MinLength:  8,
+	MaxLength:  128,
+	MinClasses: 2,
+	MinEntropy: 36,
+}
+
+// setPolicyConfig sets the configuration for the password policy.
+func setPolicyConfig(config ...PolicyConfig) PolicyConfig {
+	if len(config) == 0 {
+		return DefaultPolicyConfig
+	}
+
+	// Override default config with provided configs
+	cfg := config[0]
+
+	// Set default values if not provided
+	if cfg.MinLength == 0 {
+		cfg.MinLength = DefaultPolicyConfig.MinLength
+	}
+	if cfg.MaxLength == 0 {
+		cfg.MaxLength = DefaultPolicyConfig.MaxLength
+	}
+	if cfg.MinClasses == 0 {
+		cfg.MinClasses = DefaultPolicyConfig.MinClasses
+	}
+	if cfg.MinEntropy == 0 {
+		cfg.MinEntropy = DefaultPolicyConfig.MinEntropy
+	}
+	return cfg
+}
+
+// Policy checks the strength of passwords chosen by users.
+type Policy struct {
+	config   PolicyConfig
+	breached *BreachedList
+}
+
+func NewPolicy(config ...PolicyConfig) (*Policy, error) {
+	cfg := setPolicyConfig(config...)
+
+	breached := NewBreachedList()
+	if cfg.BreachedListFile != "" {
+		if err := breached.LoadFile(cfg.BreachedListFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Policy{config: cfg, breached: breached}, nil
+}
+
+// Check returns why the password is not acceptable, or nil. Personal is information about the user,
+// such as their name and email address, the password must not contain.
+func (p *Policy) Check(password string, personal ...string) error {
+	length := len([]rune(password))
+	if length < p.config.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", p.config.MinLength)
+	}
+	if length > p.config.MaxLength {
+		return fmt.Errorf("password must be at most %d characters long", p.config.MaxLength)
+	}
+	if p.config.MaxBytes > 0 && len(password) > p.config.MaxBytes {
+		return fmt.Errorf("password must be at most %d bytes long", p.config.MaxBytes)
+	}
+
+	if classes := characterClasses(password); classes < p.config.MinClasses {
+		return fmt.Errorf("password must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.config.MinClasses)
+	}
+
+	if containsPersonalInfo(password, personal) {
+		return fmt.Errorf("password must not contain your name or email address")
+	}
+
+	if p.breached.Contains(password) || p.breached.Contains(strings.ToLower(password)) {
+		return fmt.Errorf("password is too common and has appeared in data breaches")
+	}
+
+	if Entropy(password) < p.config.MinEntropy {
+		return fmt.Errorf("password is too easy to guess, avoid repeated characters and sequences")
+	}
+
+	return nil
+}
+
+// Entropy estimates the strength of a password in bits. Like zxcvbn, it does not trust the
+// length of a password blindly: characters repeating, continuing a sequence such as "abc"
+// or "321", or walking along a keyboard row such as "qwe" only count for one bit each.
+func Entropy(password string) float64 {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return 0
+	}
+
+	bitsPerChar := math.Log2(float64(poolSize(password)))
+	entropy := bitsPerChar
+	for i := 1; i < len(runes); i++ {
+		if isPredictable(runes[i-1], runes[i]) {
+			entropy++
+		} else {
+			entropy += bitsPerChar
+		}
+	}
+
+	return entropy
+}
+
+var keyboardRows = []string{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"}
+
+func isPredictable(prev, cur rune) bool {
+	prev, cur = unicode.ToLower(prev), unicode.ToLower(cur)
+	if prev == cur || cur == prev+1 || cur == prev-1 {
+		return true
+	}
+
+	for _, row := range keyboardRows {
+		i, j := strings.IndexRune(row, prev), strings.IndexRune(row, cur)
+		if i >= 0 && j >= 0 && (i-j == 1 || j-i == 1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func poolSize(password string) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r > unicode.MaxASCII:
+			other = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	size := 0
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	if digit {
+		size += 10
+	}
+	if symbol {
+		size += 33
+	}
+	if other {
+		size += 100
+	}
+	return size
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// containsPersonalInfo reports whether the password contains the given values, the words of
+// a name or the local part of an email address. Parts shorter than three characters are ignored.
+func containsPersonalInfo(password string, personal []string) bool {
+	password = strings.ToLower(password)
+
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		parts := strings.Fields(value)
+		if local, _, found := strings.Cut(value, "@"); found {
+			parts = append(parts, local)
+		}
+
+		for _, part := range parts {
+			if len([]rune(part)) >= 3 && strings.Contains(password, part) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+var policy, _ = NewPolicy()
+
+// SetupPolicy replaces the default password policy. It panics when the breached list cannot be loaded.
+func SetupPolicy(config ...PolicyConfig) {
+	p, err := NewPolicy(config...)
+	if err != nil {
+		panic(err)
+	}
+	policy = p
+}
+
+// CheckPolicy checks the password against the policy set up by SetupPolicy.
+func CheckPolicy(password string, personal ...string) error {
+	return policy.Check(password, personal...)
+}
@@ -0,0 +1,39 @@
+package xpassword_test
+
+import (
+	"go-fiber-template/lib/xpassword"
+	"strings"
+	"testing"
+)
+
+func TestPolicyRefusesPasswordsBcryptWouldTruncate(t *testing.T) {
+	policy, err := xpassword.NewPolicy(xpassword.PolicyConfig{MaxBytes: xpassword.BcryptMaxBytes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher := xpassword.New(xpassword.Config{Algorithm: xpassword.AlgorithmBcrypt, BcryptCost: 4})
+
+	// "é" takes two bytes, so the last case is short in characters but not in bytes
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"72 bytes", "Tr0ub4dor&3-" + strings.Repeat("x", 60), true},
+		{"73 bytes", "Tr0ub4dor&3-" + strings.Repeat("x", 61), false},
+		{"43 characters", "Tr0ub4dor&3-" + strings.Repeat("é", 31), false},
+	}
+	for _, tt := range tests {
+		err := policy.Check(tt.password)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: Check = %v, want valid %v", tt.name, err, tt.valid)
+		}
+
+		// Whatever passes the policy can be hashed
+		if err == nil {
+			if _, err := hasher.Hash(tt.password); err != nil {
+				t.Errorf("%s: Hash = %v", tt.name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package xtotp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Config holds the configuration of the TOTP generator (RFC 6238, HMAC-SHA1).
+type Config struct {
+	// Issuer is shown by authenticator apps next to the account name.
+	//
+	// Default: "go-fiber-template"
+	Issuer string
+
+	// Digits is the number of digits of a code.
+	//
+	// Default: 6
+	Digits int
+
+	// Period is the time step a code is valid for.
+	//
+	// Default: 30 * time.Second
+	Period time.Duration
+
+	// Skew is the number of periods before and after the current one that are also accepted,
+	// to tolerate clock drift between the server and the authenticator.
+	//
+	// Default: 1
+	Skew int
+
+	// Now returns the current time. Override it to validate codes against a fixed clock.
+	//
+	// Default: time.Now
+	Now func() time.Time
+}
+
+// DefaultConfig provides default values for the TOTP configuration.
+var DefaultConfig = Config{
+	Issuer: "go-fiber-template",
+	Digits: 6,
+	Period: 30 * time.Second,
+	Skew:   1,
+	Now:    time.Now,
+}
+
+// setConfig sets the configuration of the TOTP generator.
+func setConfig(config ...Config) Config {
+	if len(config) == 0 {
+		return DefaultConfig
+	}
+
+	// Override default config with provided configs
+	cfg := config[0]
+
+	// Set default values if not provided
+	if cfg.Issuer == "" {
+		cfg.Issuer = DefaultConfig.Issuer
+	}
+	if cfg.Digits == 0 {
+		cfg.Digits = DefaultConfig.Digits
+	}
+	if cfg.Period == 0 {
+		cfg.Period = DefaultConfig.Period
+	}
+	if cfg.Skew == 0 {
+		cfg.Skew = DefaultConfig.Skew
+	}
+	if cfg.Now == nil {
+		cfg.Now = DefaultConfig.Now
+	}
+
+	return cfg
+}
+
+// TOTP generates and validates time-based one-time passwords.
+type TOTP struct {
+	config Config
+}
+
+// New creates a new TOTP generator with the given configuration.
+func New(config ...Config) *TOTP {
+	return &TOTP{config: setConfig(config...)}
+}
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random base32 encoded secret.
+func (t *TOTP) GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// URI returns the otpauth:// URI that authenticator apps enroll from, usually rendered as a QR code.
+func (t *TOTP) URI(secret, accountName string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", t.config.Issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(t.config.Digits))
+	query.Set("period", fmt.Sprint(int(t.config.Period.Seconds())))
+
+	label := url.PathEscape(t.config.Issuer + ":" + accountName)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// Code returns the code for the given secret at the given time.
+func (t *TOTP) Code(secret string, at time.Time) (string, error) {
+	return t.code(secret, t.step(at))
+}
+
+// Validate checks a code against the current time, within the configured skew.
+// It returns the time step the code belongs to, which callers can store to reject replays.
+func (t *TOTP) Validate(secret, code string) (bool, int64) {
+	code = strings.TrimSpace(code)
+	if len(code) != t.config.Digits {
+		return false, 0
+	}
+
+	current := t.step(t.config.Now())
+	for offset := -t.config.Skew; offset <= t.config.Skew; offset++ {
+		step := current + int64(offset)
+		expected, err := t.code(secret, step)
+		if err != nil {
+			return false, 0
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return true, step
+		}
+	}
+
+	return false, 0
+}
+
+func (t *TOTP) step(at time.Time) int64 {
+	return at.Unix() / int64(t.config.Period.Seconds())
+}
+
+// code implements the HOTP algorithm of RFC 4226 for the given counter.
+func (t *TOTP) code(secret string, counter int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < t.config.Digits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", t.config.Digits, value%modulo), nil
+}
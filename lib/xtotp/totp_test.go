@@ -0,0 +1,81 @@
+package xtotp_test
+
+import (
+	"go-fiber-template/lib/xtotp"
+	"testing"
+	"time"
+)
+
+// secret is the SHA-1 key of the test vectors of RFC 6238, "12345678901234567890" in base32.
+const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeMatchesRFC6238(t *testing.T) {
+	totp := xtotp.New(xtotp.Config{Digits: 8})
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		code, err := totp.Code(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, code, tt.want)
+		}
+	}
+}
+
+func TestValidateAcceptsOneStepOfSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	totp := xtotp.New(xtotp.Config{Digits: 8, Now: func() time.Time { return now }})
+	current := now.Unix() / 30
+
+	tests := []struct {
+		name   string
+		offset int64
+		valid  bool
+	}{
+		{"two steps behind", -2, false},
+		{"one step behind", -1, true},
+		{"current step", 0, true},
+		{"one step ahead", 1, true},
+		{"two steps ahead", 2, false},
+	}
+	for _, tt := range tests {
+		code, err := totp.Code(secret, now.Add(time.Duration(tt.offset)*30*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		valid, step := totp.Validate(secret, code)
+		if valid != tt.valid {
+			t.Errorf("%s: Validate = %v, want %v", tt.name, valid, tt.valid)
+		}
+		if valid && step != current+tt.offset {
+			t.Errorf("%s: step = %d, want %d", tt.name, step, current+tt.offset)
+		}
+	}
+}
+
+func TestValidateRejectsMalformedCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+	totp := xtotp.New(xtotp.Config{Digits: 8, Now: func() time.Time { return now }})
+
+	for _, code := range []string{"", "9428708", "942870820", "9428708a", "94287083"} {
+		if valid, _ := totp.Validate(secret, code); valid {
+			t.Errorf("Validate(%q) = true, want false", code)
+		}
+	}
+	if valid, _ := totp.Validate(secret, " 94287082 "); !valid {
+		t.Error("Validate with surrounding spaces = false, want true")
+	}
+}
@@ -0,0 +1,51 @@
+package xvalidator
+
+import (
+	"go-fiber-template/lib/xpassword"
+	"reflect"
+
+	ut "github.com/go-playground/universal-translator"
+	val "github.com/go-playground/validator/v10"
+)
+
+// personalFields are the sibling fields a password must not contain.
+var personalFields = []string{"Name", "Email"}
+
+// PasswordValidator checks a password against the policy set up by xpassword.SetupPolicy.
+type PasswordValidator struct{}
+
+func (v *PasswordValidator) Tag() string {
+	return "x_password"
+}
+
+func (v *PasswordValidator) Func() val.Func {
+	return func(fl val.FieldLevel) bool {
+		var personal []string
+		parent := reflect.Indirect(fl.Parent())
+		if parent.Kind() == reflect.Struct {
+			for _, name := range personalFields {
+				if field := parent.FieldByName(name); field.IsValid() && field.Kind() == reflect.String {
+					personal = append(personal, field.String())
+				}
+			}
+		}
+
+		return xpassword.CheckPolicy(fl.Field().String(), personal...) == nil
+	}
+}
+
+func (v *PasswordValidator) Translation() (string, val.TranslationFunc) {
+	msg := "{0} must not contain your name or email address"
+	return msg, func(ut ut.Translator, fe val.FieldError) string {
+		// The sibling fields are gone by now, so if the password passes without them,
+		// it failed because of them.
+		if password, ok := fe.Value().(string); ok {
+			if err := xpassword.CheckPolicy(password); err != nil {
+				return err.Error()
+			}
+		}
+
+		t, _ := ut.T(fe.Tag(), fe.Field())
+		return t
+	}
+}
@@ -6,6 +6,8 @@ func Setup() {
 	var err error
 	XValidator, err = NewValidator(
 		WithCustomValidator(&DateValidator{}),
+		WithCustomValidator(&PasswordValidator{}),
+		WithCustomValidator(&SortValidator{}),
 	)
 	if err != nil {
 		panic(err)
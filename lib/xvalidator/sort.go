@@ -0,0 +1,46 @@
+package xvalidator
+
+import (
+	"slices"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	val "github.com/go-playground/validator/v10"
+)
+
+// SortValidator checks a comma-separated list of fields to sort by, such as "price,-created_at",
+// where a leading "-" sorts in descending order. The fields allowed are the space-separated
+// parameter of the tag, as in `validate:"x_sort=name price"`.
+type SortValidator struct{}
+
+func (v *SortValidator) Tag() string {
+	return "x_sort"
+}
+
+func (v *SortValidator) Func() val.Func {
+	return func(fl val.FieldLevel) bool {
+		if fl.Field().IsZero() {
+			return true
+		}
+
+		allowed := strings.Fields(fl.Param())
+		seen := map[string]bool{}
+		for _, field := range strings.Split(fl.Field().String(), ",") {
+			field = strings.TrimPrefix(field, "-")
+			if seen[field] || !slices.Contains(allowed, field) {
+				return false
+			}
+			seen[field] = true
+		}
+
+		return true
+	}
+}
+
+func (v *SortValidator) Translation() (string, val.TranslationFunc) {
+	msg := "{0} must be a comma-separated list of [{1}], each sorted in descending order when prefixed with -"
+	return msg, func(ut ut.Translator, fe val.FieldError) string {
+		t, _ := ut.T(fe.Tag(), fe.Field(), fe.Param())
+		return t
+	}
+}
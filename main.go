@@ -15,6 +15,12 @@ import "go-fiber-template/internal/infrastructure"
 // @securityDefinitions.apikey	Bearer
 // @in							header
 // @name						Authorization
+
+// @securityDefinitions.apikey	ApiKey
+// @in							header
+// @name						X-API-Key
+
+// @securityDefinitions.basic	BasicAuth
 func main() {
 	infrastructure.Run()
 }
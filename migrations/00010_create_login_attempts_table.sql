@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE login_attempts (
+    id SERIAL PRIMARY KEY,
+    throttle_key VARCHAR(255) UNIQUE NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NULL
+);
+CREATE INDEX idx_login_attempts_last_failure_at ON login_attempts(last_failure_at);
+
+INSERT INTO permissions (name) VALUES ('user:write');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin' AND p.name = 'user:write';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'user:write';
+DROP TABLE IF EXISTS login_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN disabled_at TIMESTAMP NULL;
+CREATE INDEX idx_users_created_at ON users(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_created_at;
+ALTER TABLE users DROP COLUMN disabled_at;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO permissions (name) VALUES ('user:impersonate');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin' AND p.name = 'user:impersonate';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'user:impersonate';
+-- +goose StatementEnd
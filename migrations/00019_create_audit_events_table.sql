@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+-- PostgreSQL only, like the other migrations: diff is JSONB and the append-only rule is a PL/pgSQL
+-- trigger. Other databases need diff as TEXT and have to do without the trigger.
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INT NULL,
+    impersonator_id INT NULL,
+    action VARCHAR(100) NOT NULL,
+    target_type VARCHAR(50) NOT NULL DEFAULT '',
+    target_id VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    diff JSONB NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id);
+CREATE INDEX idx_audit_events_action ON audit_events(action);
+CREATE INDEX idx_audit_events_target ON audit_events(target_type, target_id);
+CREATE INDEX idx_audit_events_request_id ON audit_events(request_id);
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at);
+
+-- The log is append-only, entries may only be deleted by the retention job and user purges.
+-- User purges may also blank the network details, and nothing else, of the events they keep.
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF NEW.ip_address = '' AND NEW.user_agent = ''
+        AND (NEW.id, NEW.actor_id, NEW.impersonator_id, NEW.action, NEW.target_type, NEW.target_id, NEW.request_id, NEW.diff, NEW.created_at)
+            IS NOT DISTINCT FROM (OLD.id, OLD.actor_id, OLD.impersonator_id, OLD.action, OLD.target_type, OLD.target_id, OLD.request_id, OLD.diff, OLD.created_at)
+    THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+BEFORE UPDATE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+INSERT INTO permissions (name) VALUES ('audit:read');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin' AND p.name = 'audit:read';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'audit:read';
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+-- +goose StatementEnd
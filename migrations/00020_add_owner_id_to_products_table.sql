@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products ADD COLUMN owner_id INT NULL REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX idx_products_owner_id ON products(owner_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_products_owner_id;
+ALTER TABLE products DROP COLUMN owner_id;
+-- +goose StatementEnd